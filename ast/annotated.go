@@ -0,0 +1,79 @@
+package ast
+
+// Annotated is implemented by all nodes that can have extended attributes.
+// Accessors are prefixed with Get and Set, since nodes expose the Annotations field directly.
+type Annotated interface {
+	Node
+	// GetAnnotations returns extended attributes of the node.
+	GetAnnotations() []*Annotation
+	// SetAnnotations replaces extended attributes of the node.
+	SetAnnotations(list []*Annotation)
+}
+
+var (
+	_ Annotated = (*Interface)(nil)
+	_ Annotated = (*Mixin)(nil)
+	_ Annotated = (*Dictionary)(nil)
+	_ Annotated = (*Namespace)(nil)
+	_ Annotated = (*Member)(nil)
+	_ Annotated = (*Constructor)(nil)
+	_ Annotated = (*Parameter)(nil)
+	_ Annotated = (*Enum)(nil)
+	_ Annotated = (*EnumValue)(nil)
+	_ Annotated = (*Typedef)(nil)
+	_ Annotated = (*Callback)(nil)
+	_ Annotated = (*CustomDecl)(nil)
+	_ Annotated = (*TypeName)(nil)
+)
+
+func (n *Interface) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Interface) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Mixin) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Mixin) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Dictionary) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Dictionary) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Namespace) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Namespace) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Member) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Member) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Constructor) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Constructor) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Parameter) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Parameter) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Enum) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Enum) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *EnumValue) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *EnumValue) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Typedef) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Typedef) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *Callback) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *Callback) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *CustomDecl) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *CustomDecl) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+func (n *TypeName) GetAnnotations() []*Annotation     { return n.Annotations }
+func (n *TypeName) SetAnnotations(list []*Annotation) { n.Annotations = list }
+
+// AllAnnotations returns all extended attributes of the node with a given name,
+// in declaration order. Unlike lookups of a single attribute, it keeps duplicates,
+// for example when [Exposed] is specified in multiple bracket groups.
+func AllAnnotations(n Annotated, name string) []*Annotation {
+	var out []*Annotation
+	for _, a := range n.GetAnnotations() {
+		if a.Name == name {
+			out = append(out, a)
+		}
+	}
+	return out
+}
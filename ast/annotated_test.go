@@ -0,0 +1,54 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotated(t *testing.T) {
+	f := parser.Parse(`[A1] interface Foo {
+	[A2] attribute long x;
+	[A3] void f([A4] long a);
+};
+[A5] interface mixin Bar {};
+[A6] dictionary Dict { [A7] long y; };
+[A8] enum E { "a" };
+[A9] typedef long T;
+[A10] callback CB = void ();`)
+	var names []string
+	ast.Walk(f, func(n ast.Node) bool {
+		if a, ok := n.(ast.Annotated); ok {
+			names = append(names, annotationNames(a.GetAnnotations())...)
+		}
+		return true
+	})
+	require.Equal(t, []string{"A1", "A2", "A3", "A4", "A5", "A6", "A7", "A8", "A9", "A10"}, names)
+
+	// strip all extended attributes
+	ast.Walk(f, func(n ast.Node) bool {
+		if a, ok := n.(ast.Annotated); ok {
+			a.SetAnnotations(nil)
+		}
+		return true
+	})
+	ast.Walk(f, func(n ast.Node) bool {
+		_, ok := n.(*ast.Annotation)
+		require.False(t, ok)
+		return true
+	})
+}
+
+func TestAllAnnotations(t *testing.T) {
+	f := parser.MustParse(`[Exposed=Window] [SecureContext, Exposed=Worker]
+interface Foo {};`)
+	iface := f.Declarations[0].(*ast.Interface)
+	list := ast.AllAnnotations(iface, "Exposed")
+	require.Len(t, list, 2)
+	require.Equal(t, "Window", list[0].Value)
+	require.Equal(t, "Worker", list[1].Value)
+	require.Len(t, ast.AllAnnotations(iface, "SecureContext"), 1)
+	require.Empty(t, ast.AllAnnotations(iface, "Global"))
+}
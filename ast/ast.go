@@ -8,7 +8,11 @@ type Base struct {
 	Start    int // rune
 	End      int // rune
 	Line     int // line number
+	EndLine  int // line number of the last token
 	Comments []string
+	Trailing []string    // comments following the node on the same line, possibly after a comma
+	Dangling []string    // comments at the end of the node body, not followed by other nodes
+	Doc      *DocComment // /** ... */ comment preceding the node
 	Errors   []*ErrorNode
 }
 
@@ -19,9 +23,18 @@ func (b *Base) NodeBase() *Base {
 // error occurred; value is text of error
 type ErrorNode struct {
 	Base
-	Message string
+	Message  string
+	Severity Severity
 }
 
+// Severity of the problem reported by ErrorNode.
+type Severity int
+
+const (
+	SeverityError   Severity = iota // invalid input
+	SeverityWarning                 // valid, but deprecated or suspicious input
+)
+
 type Decl interface {
 	Node
 	isDecl()
@@ -30,6 +43,7 @@ type Decl interface {
 // The file root node
 type File struct {
 	Base
+	Source       string // source text; only retained if requested
 	Declarations []Decl
 }
 
@@ -38,17 +52,21 @@ type Interface struct {
 	Base
 	Partial     bool
 	Callback    bool
+	Forward     bool // forward declaration without a body: interface Foo;
 	Name        string
 	Inherits    string
 	Annotations []*Annotation
 	Members     []InterfaceMember
 	CustomOps   []*CustomOp
 	Iterable    *Iterable
+	Maplike     *Maplike
+	Setlike     *Setlike
 }
 
 func (*Interface) isDecl() {}
 
 type InterfaceMember interface {
+	Node
 	isInterfaceMember()
 }
 
@@ -67,6 +85,7 @@ type Mixin struct {
 func (*Mixin) isDecl() {}
 
 type MixinMember interface {
+	Node
 	isMixinMember()
 }
 
@@ -81,6 +100,17 @@ type Dictionary struct {
 
 func (*Dictionary) isDecl() {}
 
+// namespace Foo { ... }
+type Namespace struct {
+	Base
+	Name        string
+	Partial     bool
+	Annotations []*Annotation
+	Members     []*Member
+}
+
+func (*Namespace) isDecl() {}
+
 // [Constructor], []
 type Annotation struct {
 	Base
@@ -130,14 +160,26 @@ type Member struct {
 	Const          bool
 	Readonly       bool
 	Required       bool
+	Inherit        bool // inherit attribute
 	Specialization string
 	Parameters     []*Parameter
 	Annotations    []*Annotation
+	From           string // partial declaration the member was merged from; see MergePartials
 }
 
 func (*Member) isInterfaceMember() {}
 func (*Member) isMixinMember()     {}
 
+// constructor(DOMString x)
+type Constructor struct {
+	Base
+	Annotations []*Annotation
+	Parameters  []*Parameter
+}
+
+func (*Constructor) isInterfaceMember() {}
+func (*Constructor) isMixinMember()     {}
+
 type CustomOp struct {
 	Base
 	Name string
@@ -145,22 +187,42 @@ type CustomOp struct {
 
 type TypeName struct {
 	Base
-	Name string
+	Name        string
+	Annotations []*Annotation // [Clamp] long
 }
 
 func (*TypeName) isType() {}
 
+// [async] iterable<K, V>(args)
 type Iterable struct {
 	Base
-	Key  Type
-	Elem Type
+	Async      bool
+	Key        Type
+	Elem       Type
+	Parameters []*Parameter // only for async iterables
+}
+
+// readonly maplike<K, V>
+type Maplike struct {
+	Base
+	Readonly bool
+	Key      Type
+	Elem     Type
+}
+
+// readonly setlike<T>
+type Setlike struct {
+	Base
+	Readonly bool
+	Elem     Type
 }
 
 type Callback struct {
 	Base
-	Name       string
-	Return     Type
-	Parameters []*Parameter
+	Annotations []*Annotation
+	Name        string
+	Return      Type
+	Parameters  []*Parameter
 }
 
 func (*Callback) isDecl() {}
@@ -169,11 +231,26 @@ type Enum struct {
 	Base
 	Annotations []*Annotation
 	Name        string
-	Values      []Literal
+	Values      []*EnumValue
 }
 
 func (*Enum) isDecl() {}
 
+// [Annotation] "value"
+type EnumValue struct {
+	Base
+	Annotations []*Annotation
+	Value       Literal
+}
+
+// Raw returns the enum value as written in the source, including quotes.
+func (v *EnumValue) Raw() string {
+	if l, ok := v.Value.(*BasicLiteral); ok {
+		return l.Value
+	}
+	return ""
+}
+
 type Typedef struct {
 	Base
 	Annotations []*Annotation
@@ -183,6 +260,17 @@ type Typedef struct {
 
 func (*Typedef) isDecl() {}
 
+// CustomDecl is a vendor-specific declaration produced by a dialect handler.
+type CustomDecl struct {
+	Base
+	Annotations []*Annotation
+	Keyword     string
+	Name        string
+	Data        interface{}
+}
+
+func (*CustomDecl) isDecl() {}
+
 type Type interface {
 	Node
 	isType()
@@ -232,6 +320,7 @@ type NullableType struct {
 func (*NullableType) isType() {}
 
 type Literal interface {
+	Node
 	isLiteral()
 }
 
@@ -242,6 +331,36 @@ type BasicLiteral struct {
 
 func (*BasicLiteral) isLiteral() {}
 
+// null
+type NullLiteral struct {
+	Base
+}
+
+func (*NullLiteral) isLiteral() {}
+
+// true, false
+type BoolLiteral struct {
+	Base
+	Value bool
+}
+
+func (*BoolLiteral) isLiteral() {}
+
+// String returns the literal as written in the source.
+func (l *BoolLiteral) String() string {
+	if l.Value {
+		return "true"
+	}
+	return "false"
+}
+
+// {}
+type DictionaryLiteral struct {
+	Base
+}
+
+func (*DictionaryLiteral) isLiteral() {}
+
 type SequenceLiteral struct {
 	Base
 	Elems []Literal
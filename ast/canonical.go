@@ -0,0 +1,25 @@
+package ast
+
+import "sort"
+
+// Canonicalize normalizes the tree in place to get a stable representation
+// regardless of the source formatting.
+//
+// Extended attributes of each node are sorted by name. Type names need no changes:
+// the parser already spells multi-word types (like "unsigned long long") with
+// a single space between words, regardless of the source formatting.
+func Canonicalize(f *File) {
+	Walk(f, func(n Node) bool {
+		if a, ok := n.(Annotated); ok {
+			sortAnnotations(a.GetAnnotations())
+		}
+		return true
+	})
+}
+
+// sortAnnotations sorts extended attributes by name, preserving the order of duplicates.
+func sortAnnotations(list []*Annotation) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+}
@@ -0,0 +1,40 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+// annotationNames returns names of annotations in a list.
+func annotationNames(list []*ast.Annotation) []string {
+	var out []string
+	for _, a := range list {
+		out = append(out, a.Name)
+	}
+	return out
+}
+
+func TestCanonicalize(t *testing.T) {
+	f := parser.Parse(`[SecureContext, Exposed=Window, CEReactions]
+interface Foo {
+	[Unscopable, NewObject] attribute unsigned
+		long   long x;
+	void f([Optimize, Bound] long a, [EnforceRange, Clamp] long b);
+};`)
+	iface := f.Declarations[0].(*ast.Interface)
+	m := iface.Member("x")
+
+	ast.Canonicalize(f)
+
+	require.Equal(t, []string{"CEReactions", "Exposed", "SecureContext"}, annotationNames(iface.Annotations))
+	require.Equal(t, []string{"NewObject", "Unscopable"}, annotationNames(m.Annotations))
+	require.Equal(t, "unsigned long long", m.Type.(*ast.TypeName).Name)
+
+	params := iface.Member("f").Parameters
+	require.Equal(t, []string{"Bound", "Optimize"}, annotationNames(params[0].Annotations))
+	typ := params[1].Type.(*ast.TypeName)
+	require.Equal(t, []string{"Clamp", "EnforceRange"}, annotationNames(typ.Annotations))
+}
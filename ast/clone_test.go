@@ -0,0 +1,30 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	f := parser.Parse(`[Exposed=Window]
+interface Foo {
+	attribute (long or DOMString)? x;
+	void f(optional long a = 1);
+};`)
+	c := ast.Clone(f).(*ast.File)
+	require.Equal(t, f, c)
+
+	iface := c.Declarations[0].(*ast.Interface)
+	iface.Name = "Bar"
+	iface.Annotations[0].Name = "SecureContext"
+	iface.Member("f").Parameters[0].Init.(*ast.BasicLiteral).Value = "2"
+
+	orig := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "Foo", orig.Name)
+	require.Equal(t, "Exposed", orig.Annotations[0].Name)
+	require.Equal(t, "1", orig.Member("f").Parameters[0].Init.(*ast.BasicLiteral).Value)
+	require.Nil(t, ast.Clone(nil))
+}
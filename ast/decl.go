@@ -0,0 +1,194 @@
+package ast
+
+// Member returns the first member (attribute or operation) with a given name, or nil.
+// For overloaded operations only the first overload is returned.
+func (i *Interface) Member(name string) *Member {
+	for _, m := range i.Members {
+		if m, ok := m.(*Member); ok && m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Stringifier returns the attribute or operation declared with the stringifier keyword, or nil.
+// For the bare "stringifier;" form there is no such member, see HasStringifier.
+func (i *Interface) Stringifier() *Member {
+	for _, m := range i.Members {
+		if m, ok := m.(*Member); ok && m.Specialization == "stringifier" {
+			return m
+		}
+	}
+	return nil
+}
+
+// HasStringifier reports whether the interface defines a stringification behavior,
+// either with a stringifier member or with the bare "stringifier;" declaration.
+func (i *Interface) HasStringifier() bool {
+	for _, op := range i.CustomOps {
+		if op.Name == "stringifier" {
+			return true
+		}
+	}
+	return i.Stringifier() != nil
+}
+
+// ToJSON returns the toJSON operation of the interface, with or without [Default], or nil.
+// For the legacy "serializer;" and "jsonifier;" declarations it returns an equivalent
+// [Default] object toJSON() operation positioned at the declaration.
+func (i *Interface) ToJSON() *Member {
+	for _, m := range i.Members {
+		if m, ok := m.(*Member); ok && m.Name == "toJSON" && !m.Attribute && !m.Const && !m.Static {
+			return m
+		}
+	}
+	for _, op := range i.CustomOps {
+		if op.Name == "serializer" || op.Name == "jsonifier" {
+			pos := Base{Start: op.Start, End: op.End, Line: op.Line, EndLine: op.EndLine}
+			return &Member{
+				Base:        pos,
+				Name:        "toJSON",
+				Type:        &TypeName{Base: pos, Name: "object"},
+				Annotations: []*Annotation{{Base: pos, Name: "Default"}},
+			}
+		}
+	}
+	return nil
+}
+
+// ArityRange returns the minimal number of required arguments and the maximal number of
+// arguments across all overloads of the operation with a given name. If any overload has
+// a variadic parameter, max is -1. Both values are zero if there is no such operation.
+func (i *Interface) ArityRange(name string) (min, max int) {
+	found := false
+	for _, m := range i.Members {
+		m, ok := m.(*Member)
+		if !ok || m.Name != name || m.Attribute || m.Const {
+			continue
+		}
+		req, total := 0, len(m.Parameters)
+		for _, p := range m.Parameters {
+			if p.Variadic {
+				total = -1
+			} else if !p.Optional {
+				req++
+			}
+		}
+		if !found || req < min {
+			min = req
+		}
+		if !found || max >= 0 && (total < 0 || total > max) {
+			max = total
+		}
+		found = true
+	}
+	return min, max
+}
+
+// Member returns the dictionary member with a given name, or nil.
+func (d *Dictionary) Member(name string) *Member {
+	for _, m := range d.Members {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// StringValues returns values of the enum without quotes.
+func (e *Enum) StringValues() []string {
+	out := make([]string, 0, len(e.Values))
+	for _, v := range e.Values {
+		out = append(out, unquote(v.Raw()))
+	}
+	return out
+}
+
+// Contains checks if the enum has a given value. The value must not be quoted.
+func (e *Enum) Contains(value string) bool {
+	for _, v := range e.Values {
+		if unquote(v.Raw()) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// unquote removes quotes around a string literal. WebIDL strings have no escape sequences.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// EachInterface calls fn for each interface declared in the file, in source order.
+func (f *File) EachInterface(fn func(*Interface)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Interface); ok {
+			fn(d)
+		}
+	}
+}
+
+// EachMixin calls fn for each interface mixin declared in the file, in source order.
+func (f *File) EachMixin(fn func(*Mixin)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Mixin); ok {
+			fn(d)
+		}
+	}
+}
+
+// EachDictionary calls fn for each dictionary declared in the file, in source order.
+func (f *File) EachDictionary(fn func(*Dictionary)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Dictionary); ok {
+			fn(d)
+		}
+	}
+}
+
+// EachNamespace calls fn for each namespace declared in the file, in source order.
+func (f *File) EachNamespace(fn func(*Namespace)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Namespace); ok {
+			fn(d)
+		}
+	}
+}
+
+// EachEnum calls fn for each enum declared in the file, in source order.
+func (f *File) EachEnum(fn func(*Enum)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Enum); ok {
+			fn(d)
+		}
+	}
+}
+
+// EachTypedef calls fn for each typedef declared in the file, in source order.
+func (f *File) EachTypedef(fn func(*Typedef)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Typedef); ok {
+			fn(d)
+		}
+	}
+}
+
+// EachCallback calls fn for each callback function declared in the file, in source order.
+func (f *File) EachCallback(fn func(*Callback)) {
+	for _, d := range f.Declarations {
+		if d, ok := d.(*Callback); ok {
+			fn(d)
+		}
+	}
+}
+
+// sourceOf returns the source text for a given node position.
+func (f *File) sourceOf(b *Base) string {
+	if b.Start < 0 || b.End < b.Start || b.End >= len(f.Source) {
+		return ""
+	}
+	return f.Source[b.Start : b.End+1]
+}
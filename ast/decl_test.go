@@ -0,0 +1,159 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterfaceMember(t *testing.T) {
+	f := parser.Parse(`interface Foo {
+	attribute long x;
+	void f(long a);
+	void f(DOMString a);
+};`)
+	iface := f.Declarations[0].(*ast.Interface)
+
+	m := iface.Member("x")
+	require.NotNil(t, m)
+	require.True(t, m.Attribute)
+
+	m = iface.Member("f")
+	require.NotNil(t, m)
+	require.Equal(t, "long", m.Parameters[0].Type.(*ast.TypeName).Name)
+
+	require.Nil(t, iface.Member("y"))
+}
+
+func TestDictionaryMember(t *testing.T) {
+	f := parser.Parse(`dictionary Foo {
+	long x = 1;
+	required DOMString y;
+};`)
+	dict := f.Declarations[0].(*ast.Dictionary)
+
+	m := dict.Member("y")
+	require.NotNil(t, m)
+	require.True(t, m.Required)
+
+	require.Nil(t, dict.Member("z"))
+}
+
+func TestEachInterface(t *testing.T) {
+	f := parser.Parse(`interface Foo {};
+dictionary Dict {};
+partial interface Foo {};
+enum E { "a" };
+callback interface Bar {};`)
+	var names []string
+	f.EachInterface(func(iface *ast.Interface) {
+		names = append(names, iface.Name)
+	})
+	require.Equal(t, []string{"Foo", "Foo", "Bar"}, names)
+
+	n := 0
+	f.EachDictionary(func(*ast.Dictionary) { n++ })
+	f.EachEnum(func(*ast.Enum) { n++ })
+	f.EachTypedef(func(*ast.Typedef) { n++ })
+	require.Equal(t, 2, n)
+}
+
+func TestInterfaceStringifier(t *testing.T) {
+	f := parser.Parse(`interface URL {
+	stringifier attribute USVString href;
+	attribute USVString origin;
+};
+interface Location {
+	stringifier;
+	attribute USVString href;
+};
+interface Range {
+	stringifier DOMString ();
+};
+interface Node {
+	attribute DOMString name;
+};`)
+	url := f.Declarations[0].(*ast.Interface)
+	m := url.Stringifier()
+	require.NotNil(t, m)
+	require.Equal(t, "href", m.Name)
+	require.True(t, m.Attribute)
+	require.True(t, url.HasStringifier())
+
+	loc := f.Declarations[1].(*ast.Interface)
+	require.Nil(t, loc.Stringifier())
+	require.True(t, loc.HasStringifier())
+
+	rng := f.Declarations[2].(*ast.Interface)
+	m = rng.Stringifier()
+	require.NotNil(t, m)
+	require.False(t, m.Attribute)
+	require.Equal(t, "DOMString", m.Type.(*ast.TypeName).Name)
+
+	node := f.Declarations[3].(*ast.Interface)
+	require.Nil(t, node.Stringifier())
+	require.False(t, node.HasStringifier())
+}
+
+func TestInterfaceToJSON(t *testing.T) {
+	f := parser.Parse(`interface Point {
+	[Default] object toJSON();
+	attribute double x;
+};
+interface Legacy {
+	serializer;
+};
+interface Node {
+	attribute DOMString toJSON;
+};`)
+	pt := f.Declarations[0].(*ast.Interface)
+	m := pt.ToJSON()
+	require.NotNil(t, m)
+	require.Equal(t, "toJSON", m.Name)
+	require.Equal(t, 2, m.Line)
+
+	legacy := f.Declarations[1].(*ast.Interface)
+	m = legacy.ToJSON()
+	require.NotNil(t, m)
+	require.Equal(t, "toJSON", m.Name)
+	require.Equal(t, "object", m.Type.(*ast.TypeName).Name)
+	require.Equal(t, "Default", m.Annotations[0].Name)
+	require.Equal(t, 6, m.Line)
+
+	node := f.Declarations[2].(*ast.Interface)
+	require.Nil(t, node.ToJSON())
+}
+
+func TestInterfaceArityRange(t *testing.T) {
+	f := parser.Parse(`interface Foo {
+	void f(long a, long b);
+	void f(DOMString a, optional long b, optional long c);
+	void g();
+	void g(long a, long... rest);
+	void h(optional long a);
+	attribute long x;
+};`)
+	iface := f.Declarations[0].(*ast.Interface)
+	check := func(name string, expMin, expMax int) {
+		min, max := iface.ArityRange(name)
+		require.Equal(t, expMin, min, name)
+		require.Equal(t, expMax, max, name)
+	}
+	check("f", 1, 3)
+	check("g", 0, -1)
+	check("h", 0, 1)
+	check("x", 0, 0)
+	check("y", 0, 0)
+}
+
+func TestEnumValues(t *testing.T) {
+	f := parser.Parse(`enum RequestMode { "navigate", "same-origin", "" };`)
+	e := f.Declarations[0].(*ast.Enum)
+	require.Equal(t, []string{"navigate", "same-origin", ""}, e.StringValues())
+	require.True(t, e.Contains("same-origin"))
+	require.True(t, e.Contains(""))
+	require.False(t, e.Contains(`"navigate"`))
+	require.False(t, e.Contains("cors"))
+}
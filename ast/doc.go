@@ -0,0 +1,16 @@
+package ast
+
+import "strings"
+
+// DocComment is a documentation comment in a /** ... */ form.
+type DocComment struct {
+	Lines []string // text lines with comment markers and '*' margins removed
+}
+
+// Text returns the text of the comment.
+func (d *DocComment) Text() string {
+	if d == nil {
+		return ""
+	}
+	return strings.Join(d.Lines, "\n")
+}
@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Warnings returns all warnings reported for the file.
+func (f *File) Warnings() []*ErrorNode {
+	return collectErrors(f, SeverityWarning)
+}
+
+// collectErrors returns all error nodes of a given severity attached to the tree,
+// sorted by position.
+func collectErrors(n Node, sev Severity) []*ErrorNode {
+	var out []*ErrorNode
+	Walk(n, func(n Node) bool {
+		for _, e := range n.NodeBase().Errors {
+			if e.Severity == sev {
+				out = append(out, e)
+			}
+		}
+		return true
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start < out[j].Start
+	})
+	return out
+}
+
+// AllErrors returns all errors (but not warnings) reported for the node and its children,
+// sorted by position.
+func AllErrors(n Node) []*ErrorNode {
+	return collectErrors(n, SeverityError)
+}
+
+// Error implements the error interface.
+func (e *ErrorNode) Error() string {
+	return fmt.Sprintf("%d: %s", e.Line, e.Message)
+}
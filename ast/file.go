@@ -0,0 +1,101 @@
+package ast
+
+import "fmt"
+
+// FlattenedMembers returns all members an instance of a given interface has: members of
+// the interface itself, its partial declarations, included mixins and all inherited interfaces.
+//
+// Inherited members come first. If an interface declares a member with the same name as
+// its parent interface, members of the parent with that name are overridden.
+// An error is returned if any interface in the chain is not defined or if an inheritance cycle is found.
+func (f *File) FlattenedMembers(interfaceName string) ([]*Member, error) {
+	var (
+		ifaces   = make(map[string][]*Interface)
+		mixins   = make(map[string][]*Mixin)
+		includes = make(map[string][]string)
+	)
+	for _, d := range f.Declarations {
+		switch d := d.(type) {
+		case *Interface:
+			ifaces[d.Name] = append(ifaces[d.Name], d)
+		case *Mixin:
+			mixins[d.Name] = append(mixins[d.Name], d)
+		case *Includes:
+			includes[d.Name] = append(includes[d.Name], d.Source)
+		case *Implementation:
+			includes[d.Name] = append(includes[d.Name], d.Source)
+		}
+	}
+
+	// collect the inheritance chain, starting from the most derived interface
+	var (
+		chain []string
+		seen  = make(map[string]bool)
+	)
+	for name := interfaceName; name != ""; {
+		if seen[name] {
+			return nil, fmt.Errorf("inheritance cycle found for interface %q", name)
+		}
+		seen[name] = true
+		decls, ok := ifaces[name]
+		if !ok {
+			return nil, fmt.Errorf("interface %q is not defined", name)
+		}
+		chain = append(chain, name)
+		name = ""
+		for _, d := range decls {
+			if d.Inherits != "" {
+				name = d.Inherits
+				break
+			}
+		}
+	}
+
+	// members declared by an interface, including partials and mixins
+	own := func(name string) []*Member {
+		var out []*Member
+		for _, d := range ifaces[name] {
+			for _, m := range d.Members {
+				if m, ok := m.(*Member); ok {
+					out = append(out, m)
+				}
+			}
+		}
+		for _, src := range includes[name] {
+			for _, d := range mixins[src] {
+				for _, m := range d.Members {
+					if m, ok := m.(*Member); ok {
+						out = append(out, m)
+					}
+				}
+			}
+			for _, d := range ifaces[src] {
+				for _, m := range d.Members {
+					if m, ok := m.(*Member); ok {
+						out = append(out, m)
+					}
+				}
+			}
+		}
+		return out
+	}
+
+	var out []*Member
+	for i := len(chain) - 1; i >= 0; i-- {
+		members := own(chain[i])
+		overridden := make(map[string]bool)
+		for _, m := range members {
+			if m.Name != "" {
+				overridden[m.Name] = true
+			}
+		}
+		filtered := out[:0]
+		for _, m := range out {
+			if m.Name == "" || !overridden[m.Name] {
+				filtered = append(filtered, m)
+			}
+		}
+		out = append(filtered, members...)
+	}
+	return out, nil
+}
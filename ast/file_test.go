@@ -0,0 +1,52 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+// memberNames returns names of members in a list.
+func memberNames(list []*ast.Member) []string {
+	var out []string
+	for _, m := range list {
+		out = append(out, m.Name)
+	}
+	return out
+}
+
+func TestFlattenedMembers(t *testing.T) {
+	f := parser.Parse(`interface Base {
+	attribute long id;
+	void reset();
+};
+interface mixin Mixed {
+	attribute DOMString label;
+};
+interface Derived : Base {
+	void reset(long x);
+};
+partial interface Derived {
+	attribute boolean extra;
+};
+Derived includes Mixed;
+interface A : B {};
+interface B : A {};
+`)
+	members, err := f.FlattenedMembers("Derived")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "reset", "extra", "label"}, memberNames(members))
+	require.Len(t, members[1].Parameters, 1)
+
+	members, err = f.FlattenedMembers("Base")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "reset"}, memberNames(members))
+
+	_, err = f.FlattenedMembers("A")
+	require.Error(t, err)
+
+	_, err = f.FlattenedMembers("Unknown")
+	require.Error(t, err)
+}
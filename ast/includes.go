@@ -0,0 +1,35 @@
+package ast
+
+// IncludeGraph maps names of interfaces to the names of mixins they include, in source order.
+// Both includes statements and deprecated implements statements are considered.
+// Repeated statements are listed once.
+func IncludeGraph(f *File) map[string][]string {
+	g := make(map[string][]string)
+	add := func(name, src string) {
+		for _, s := range g[name] {
+			if s == src {
+				return
+			}
+		}
+		g[name] = append(g[name], src)
+	}
+	for _, d := range f.Declarations {
+		switch d := d.(type) {
+		case *Includes:
+			add(d.Name, d.Source)
+		case *Implementation:
+			add(d.Name, d.Source)
+		}
+	}
+	return g
+}
+
+// NormalizeImplements replaces deprecated implements statements of the file
+// with equivalent includes statements, keeping positions and comments.
+func NormalizeImplements(f *File) {
+	for i, d := range f.Declarations {
+		if d, ok := d.(*Implementation); ok {
+			f.Declarations[i] = &Includes{Base: d.Base, Name: d.Name, Source: d.Source}
+		}
+	}
+}
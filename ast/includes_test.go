@@ -0,0 +1,43 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludeGraph(t *testing.T) {
+	f := parser.Parse(`interface Document {};
+interface mixin DocumentOrShadowRoot {};
+interface mixin ParentNode {};
+Document includes DocumentOrShadowRoot;
+Document includes ParentNode;
+Window implements WindowEventHandlers;
+Document includes ParentNode;`)
+	require.Equal(t, map[string][]string{
+		"Document": {"DocumentOrShadowRoot", "ParentNode"},
+		"Window":   {"WindowEventHandlers"},
+	}, ast.IncludeGraph(f))
+}
+
+func TestNormalizeImplements(t *testing.T) {
+	f := parser.Parse(`interface Window {};
+// legacy
+Window implements WindowEventHandlers;
+Document includes ParentNode;
+Window implements GlobalEventHandlers;`)
+	before := ast.IncludeGraph(f)
+	ast.NormalizeImplements(f)
+	require.Len(t, f.Declarations, 4)
+	for _, d := range f.Declarations[1:] {
+		require.IsType(t, &ast.Includes{}, d)
+	}
+	inc := f.Declarations[1].(*ast.Includes)
+	require.Equal(t, "Window", inc.Name)
+	require.Equal(t, "WindowEventHandlers", inc.Source)
+	require.Equal(t, []string{"// legacy"}, inc.Comments)
+	require.Equal(t, 3, inc.Line)
+	require.Equal(t, before, ast.IncludeGraph(f))
+}
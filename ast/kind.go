@@ -0,0 +1,113 @@
+package ast
+
+// TypeKind is a category of a named type.
+type TypeKind int
+
+const (
+	KindReference TypeKind = iota // user-defined type, like an interface or dictionary
+	KindAny                       // any
+	KindUndefined                 // undefined, void
+	KindBoolean                   // boolean
+	KindInteger                   // byte, octet, short, long, long long and unsigned variants
+	KindFloat                     // float, double and unrestricted variants
+	KindBigInt                    // bigint
+	KindString                    // DOMString, ByteString, USVString, CSSOMString
+	KindObject                    // object
+	KindSymbol                    // symbol
+	KindBuffer                    // ArrayBuffer, typed arrays and buffer views
+	KindDate                      // legacy Date type
+	KindException                 // DOMException, Error
+	KindGeneric                   // Promise, sequence, record, FrozenArray, ObservableArray
+)
+
+var kindNames = [...]string{
+	KindReference: "reference",
+	KindAny:       "any",
+	KindUndefined: "undefined",
+	KindBoolean:   "boolean",
+	KindInteger:   "integer",
+	KindFloat:     "float",
+	KindBigInt:    "bigint",
+	KindString:    "string",
+	KindObject:    "object",
+	KindSymbol:    "symbol",
+	KindBuffer:    "buffer",
+	KindDate:      "date",
+	KindException: "exception",
+	KindGeneric:   "generic",
+}
+
+func (k TypeKind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// typeKinds maps names of types defined by the WebIDL specification to their kinds.
+// Generic types are listed by the name used before type arguments.
+var typeKinds = map[string]TypeKind{
+	"any":                     KindAny,
+	"undefined":               KindUndefined,
+	"void":                    KindUndefined,
+	"boolean":                 KindBoolean,
+	"byte":                    KindInteger,
+	"octet":                   KindInteger,
+	"short":                   KindInteger,
+	"unsigned short":          KindInteger,
+	"long":                    KindInteger,
+	"unsigned long":           KindInteger,
+	"long long":               KindInteger,
+	"unsigned long long":      KindInteger,
+	"float":                   KindFloat,
+	"unrestricted float":      KindFloat,
+	"double":                  KindFloat,
+	"unrestricted double":     KindFloat,
+	"bigint":                  KindBigInt,
+	"DOMString":               KindString,
+	"ByteString":              KindString,
+	"USVString":               KindString,
+	"CSSOMString":             KindString,
+	"object":                  KindObject,
+	"symbol":                  KindSymbol,
+	"ArrayBuffer":             KindBuffer,
+	"SharedArrayBuffer":       KindBuffer,
+	"DataView":                KindBuffer,
+	"Int8Array":               KindBuffer,
+	"Int16Array":              KindBuffer,
+	"Int32Array":              KindBuffer,
+	"Uint8Array":              KindBuffer,
+	"Uint16Array":             KindBuffer,
+	"Uint32Array":             KindBuffer,
+	"Uint8ClampedArray":       KindBuffer,
+	"BigInt64Array":           KindBuffer,
+	"BigUint64Array":          KindBuffer,
+	"Float32Array":            KindBuffer,
+	"Float64Array":            KindBuffer,
+	"ArrayBufferView":         KindBuffer,
+	"BufferSource":            KindBuffer,
+	"AllowSharedBufferSource": KindBuffer,
+	"Date":                    KindDate,
+	"DOMException":            KindException,
+	"Error":                   KindException,
+	"Promise":                 KindGeneric,
+	"sequence":                KindGeneric,
+	"record":                  KindGeneric,
+	"FrozenArray":             KindGeneric,
+	"ObservableArray":         KindGeneric,
+}
+
+// IsBuiltin checks if the name refers to a type defined by the WebIDL specification.
+func IsBuiltin(name string) bool {
+	_, ok := typeKinds[name]
+	return ok
+}
+
+// Kind classifies the type by its name. Names that are not built into WebIDL
+// are reported as KindReference.
+func (t *TypeName) Kind() TypeKind {
+	if k, ok := typeKinds[t.Name]; ok {
+		return k
+	}
+	return KindReference
+}
@@ -0,0 +1,118 @@
+package ast
+
+import "strings"
+
+// IsReadOnly reports whether the member can't be assigned to.
+// This is the case for constants, readonly attributes and attributes
+// marked with [Readonly] or [SameObject] (which implies readonly).
+func (m *Member) IsReadOnly() bool {
+	if m.Readonly || m.Const {
+		return true
+	}
+	return findAnnotation(m.Annotations, "Readonly") != nil ||
+		findAnnotation(m.Annotations, "SameObject") != nil
+}
+
+// ReturnType returns the return type of an operation, or nil if the member is
+// an attribute or a constant.
+func (m *Member) ReturnType() Type {
+	if m.Attribute || m.Const {
+		return nil
+	}
+	return m.Type
+}
+
+// AttributeType returns the type of an attribute or a dictionary member,
+// or nil if the member is an operation or a constant.
+func (m *Member) AttributeType() Type {
+	if !m.Attribute {
+		return nil
+	}
+	return m.Type
+}
+
+// MemberFlags is a set of modifiers of a member.
+type MemberFlags uint
+
+const (
+	MemberStringifier MemberFlags = 1 << iota
+	MemberGetter
+	MemberSetter
+	MemberDeleter
+	MemberStatic
+	MemberConst
+	MemberReadonly
+	MemberRequired
+	MemberInherit
+	MemberAttribute
+)
+
+// memberFlagNames are names of flags, in the order of bits.
+var memberFlagNames = []string{
+	"stringifier", "getter", "setter", "deleter",
+	"static", "const", "readonly", "required", "inherit", "attribute",
+}
+
+// Has checks if all given flags are set.
+func (f MemberFlags) Has(flags MemberFlags) bool {
+	return f&flags == flags
+}
+
+// String returns names of the flags separated by "|", in the order of IDL keywords,
+// like "static|readonly|attribute".
+func (f MemberFlags) String() string {
+	var names []string
+	for i, name := range memberFlagNames {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Flags returns modifiers of the member as a set, including the special operation kind.
+func (m *Member) Flags() MemberFlags {
+	var f MemberFlags
+	for _, mod := range []struct {
+		set  bool
+		flag MemberFlags
+	}{
+		{m.Attribute, MemberAttribute},
+		{m.Static, MemberStatic},
+		{m.Readonly, MemberReadonly},
+		{m.Const, MemberConst},
+		{m.Required, MemberRequired},
+		{m.Inherit, MemberInherit},
+		{m.Specialization == "stringifier", MemberStringifier},
+		{m.Specialization == "getter", MemberGetter},
+		{m.Specialization == "setter", MemberSetter},
+		{m.Specialization == "deleter", MemberDeleter},
+	} {
+		if mod.set {
+			f |= mod.flag
+		}
+	}
+	return f
+}
+
+// Source returns the source text of the member, including leading annotations.
+// It returns an empty string if the file doesn't retain the source text.
+func (m *Member) Source(f *File) string {
+	return f.sourceOf(&m.Base)
+}
+
+// EffectivelyOptional reports whether an argument can be omitted in a call.
+// This is the case for optional and variadic parameters.
+func (p *Parameter) EffectivelyOptional() bool {
+	return p.Optional || p.Variadic
+}
+
+// findAnnotation returns the first annotation with a given name, or nil.
+func findAnnotation(list []*Annotation, name string) *Annotation {
+	for _, a := range list {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
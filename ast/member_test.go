@@ -0,0 +1,105 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+// parseMembers parses a single interface and returns its members.
+func parseMembers(t *testing.T, src string) []*ast.Member {
+	f := parser.Parse(src)
+	require.Len(t, f.Declarations, 1)
+	iface, ok := f.Declarations[0].(*ast.Interface)
+	require.True(t, ok)
+	var out []*ast.Member
+	for _, m := range iface.Members {
+		out = append(out, m.(*ast.Member))
+	}
+	return out
+}
+
+func TestMemberIsReadOnly(t *testing.T) {
+	members := parseMembers(t, `interface Foo {
+	readonly attribute long a;
+	attribute long b;
+	[SameObject] attribute Node c;
+	[Readonly] attribute long d;
+	const long e = 1;
+};`)
+	require.Len(t, members, 5)
+	exp := []bool{true, false, true, true, true}
+	for i, m := range members {
+		require.Equal(t, exp[i], m.IsReadOnly(), m.Name)
+	}
+}
+
+func TestMemberTypes(t *testing.T) {
+	members := parseMembers(t, `interface Foo {
+	attribute DOMString a;
+	Promise<long> f();
+	const long C = 1;
+};`)
+	require.Len(t, members, 3)
+	attr, op, c := members[0], members[1], members[2]
+	require.Equal(t, "DOMString", attr.AttributeType().(*ast.TypeName).Name)
+	require.Nil(t, attr.ReturnType())
+	require.Equal(t, "Promise", op.ReturnType().(*ast.ParametrizedType).Name)
+	require.Nil(t, op.AttributeType())
+	require.Nil(t, c.ReturnType())
+	require.Nil(t, c.AttributeType())
+}
+
+func TestMemberSource(t *testing.T) {
+	const src = `interface Foo {
+	readonly attribute long x;
+	[SameObject] readonly attribute Node y;
+	void f(optional long a = 0);
+};`
+	f, err := parser.ParseWith(src, parser.Options{KeepSource: true})
+	require.NoError(t, err)
+	iface := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "readonly attribute long x", iface.Member("x").Source(f))
+	require.Equal(t, "[SameObject] readonly attribute Node y", iface.Member("y").Source(f))
+	require.Equal(t, "void f(optional long a = 0)", iface.Member("f").Source(f))
+
+	f = parser.Parse(src)
+	iface = f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "", iface.Member("x").Source(f))
+}
+
+func TestParameterEffectivelyOptional(t *testing.T) {
+	members := parseMembers(t, `interface Foo {
+	void f(long a, optional long b = 0, long... rest);
+};`)
+	params := members[0].Parameters
+	require.Len(t, params, 3)
+	require.False(t, params[0].EffectivelyOptional())
+	require.True(t, params[1].EffectivelyOptional())
+	require.True(t, params[2].EffectivelyOptional())
+}
+
+func TestMemberFlags(t *testing.T) {
+	members := parseMembers(t, `interface Foo {
+	static readonly attribute long a;
+	inherit attribute DOMString b;
+	getter long (unsigned long index);
+	const long C = 1;
+	void f();
+};`)
+	require.Len(t, members, 5)
+	f := members[0].Flags()
+	require.Equal(t, ast.MemberStatic|ast.MemberReadonly|ast.MemberAttribute, f)
+	require.Equal(t, "static|readonly|attribute", f.String())
+	require.True(t, f.Has(ast.MemberStatic|ast.MemberAttribute))
+	require.False(t, f.Has(ast.MemberStatic|ast.MemberConst))
+
+	require.Equal(t, ast.MemberInherit|ast.MemberAttribute, members[1].Flags())
+	require.Equal(t, "b", members[1].Name)
+	require.Equal(t, ast.MemberGetter, members[2].Flags())
+	require.Equal(t, ast.MemberConst, members[3].Flags())
+	require.Equal(t, ast.MemberFlags(0), members[4].Flags())
+	require.Equal(t, "", members[4].Flags().String())
+}
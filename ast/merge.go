@@ -0,0 +1,140 @@
+package ast
+
+import "fmt"
+
+// Merge appends declarations of the other file to this file.
+// Declarations with the same name are all kept, so validation can report conflicts;
+// use MergePartials to fold partial declarations afterwards.
+// Nodes are shared with the other file, and source text of this file is dropped,
+// since positions of merged nodes refer to a different source.
+func (f *File) Merge(other *File) {
+	f.Declarations = append(f.Declarations, other.Declarations...)
+	f.Errors = append(f.Errors, other.Errors...)
+	f.Source = ""
+}
+
+// MergeOptions controls how MergePartials merges declarations.
+type MergeOptions struct {
+	// TagOrigin sets the From field of merged members to the partial declaration they came from.
+	TagOrigin bool
+}
+
+// MergePartials returns a copy of the file with members of partial interfaces, mixins,
+// dictionaries and namespaces folded into the primary declarations with the same name.
+// Merged members retain positions and comments from the partial declaration, and extended
+// attributes of partial declarations are appended to the primary ones.
+// An interface can only have one iterable, maplike or setlike declaration, so a conflicting
+// declaration in a partial interface is reported as an error on the primary declaration.
+// Partial declarations without a primary declaration are kept as is.
+func MergePartials(f *File, opt MergeOptions) *File {
+	f = Clone(f).(*File)
+	var (
+		ifaces = make(map[string]*Interface)
+		mixins = make(map[string]*Mixin)
+		dicts  = make(map[string]*Dictionary)
+		spaces = make(map[string]*Namespace)
+	)
+	for _, d := range f.Declarations {
+		switch d := d.(type) {
+		case *Interface:
+			if !d.Partial && ifaces[d.Name] == nil {
+				ifaces[d.Name] = d
+			}
+		case *Mixin:
+			if !d.Partial && mixins[d.Name] == nil {
+				mixins[d.Name] = d
+			}
+		case *Dictionary:
+			if !d.Partial && dicts[d.Name] == nil {
+				dicts[d.Name] = d
+			}
+		case *Namespace:
+			if !d.Partial && spaces[d.Name] == nil {
+				spaces[d.Name] = d
+			}
+		}
+	}
+	origin := func(kind, name string, line int) string {
+		if !opt.TagOrigin {
+			return ""
+		}
+		return fmt.Sprintf("partial %s %s at line %d", kind, name, line)
+	}
+	tag := func(list []*Member, from string) []*Member {
+		for _, m := range list {
+			m.From = from
+		}
+		return list
+	}
+	decls := f.Declarations[:0]
+	for _, d := range f.Declarations {
+		switch d := d.(type) {
+		case *Interface:
+			if dst := ifaces[d.Name]; d.Partial && dst != nil {
+				from := origin("interface", d.Name, d.Line)
+				for _, m := range d.Members {
+					if m, ok := m.(*Member); ok {
+						m.From = from
+					}
+				}
+				dst.Annotations = append(dst.Annotations, d.Annotations...)
+				dst.Members = append(dst.Members, d.Members...)
+				dst.CustomOps = append(dst.CustomOps, d.CustomOps...)
+				mergeCollections(dst, d)
+				continue
+			}
+		case *Mixin:
+			if dst := mixins[d.Name]; d.Partial && dst != nil {
+				from := origin("interface mixin", d.Name, d.Line)
+				for _, m := range d.Members {
+					if m, ok := m.(*Member); ok {
+						m.From = from
+					}
+				}
+				dst.Annotations = append(dst.Annotations, d.Annotations...)
+				dst.Members = append(dst.Members, d.Members...)
+				dst.CustomOps = append(dst.CustomOps, d.CustomOps...)
+				if dst.Iterable == nil {
+					dst.Iterable = d.Iterable
+				}
+				continue
+			}
+		case *Dictionary:
+			if dst := dicts[d.Name]; d.Partial && dst != nil {
+				dst.Annotations = append(dst.Annotations, d.Annotations...)
+				dst.Members = append(dst.Members, tag(d.Members, origin("dictionary", d.Name, d.Line))...)
+				continue
+			}
+		case *Namespace:
+			if dst := spaces[d.Name]; d.Partial && dst != nil {
+				dst.Annotations = append(dst.Annotations, d.Annotations...)
+				dst.Members = append(dst.Members, tag(d.Members, origin("namespace", d.Name, d.Line))...)
+				continue
+			}
+		}
+		decls = append(decls, d)
+	}
+	f.Declarations = decls
+	return f
+}
+
+// mergeCollections moves the iterable, maplike or setlike declaration of a partial interface
+// to the primary one, or reports an error if the primary interface already has one.
+func mergeCollections(dst, src *Interface) {
+	var n Node
+	switch {
+	case src.Iterable != nil:
+		n = src.Iterable
+	case src.Maplike != nil:
+		n = src.Maplike
+	case src.Setlike != nil:
+		n = src.Setlike
+	default:
+		return
+	}
+	if dst.Iterable != nil || dst.Maplike != nil || dst.Setlike != nil {
+		dst.Errors = append(dst.Errors, nodeError(n, "Interface %s can only have one iterable, maplike or setlike declaration", dst.Name))
+		return
+	}
+	dst.Iterable, dst.Maplike, dst.Setlike = src.Iterable, src.Maplike, src.Setlike
+}
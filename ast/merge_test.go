@@ -0,0 +1,126 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePartials(t *testing.T) {
+	f := parser.Parse(`interface Foo {
+	attribute long x;
+};
+dictionary Dict {
+	long a;
+};
+partial interface Foo {
+	// comment
+	attribute long y;
+};
+partial dictionary Dict {
+	long b;
+};
+partial interface Bar {
+	attribute long z;
+};`)
+	m := ast.MergePartials(f, ast.MergeOptions{TagOrigin: true})
+	require.Len(t, m.Declarations, 3)
+	// the original tree is not modified
+	require.Len(t, f.Declarations, 5)
+
+	foo := m.Declarations[0].(*ast.Interface)
+	require.Len(t, foo.Members, 2)
+	require.Equal(t, "", foo.Member("x").From)
+	y := foo.Member("y")
+	require.Equal(t, "partial interface Foo at line 7", y.From)
+	require.Equal(t, 9, y.Line)
+	require.Equal(t, []string{"// comment"}, y.Comments)
+
+	dict := m.Declarations[1].(*ast.Dictionary)
+	require.Equal(t, []string{"a", "b"}, memberNames(dict.Members))
+	require.Equal(t, "partial dictionary Dict at line 11", dict.Member("b").From)
+
+	bar := m.Declarations[2].(*ast.Interface)
+	require.True(t, bar.Partial)
+	require.Equal(t, "", bar.Member("z").From)
+
+	m = ast.MergePartials(f, ast.MergeOptions{})
+	require.Equal(t, "", m.Declarations[0].(*ast.Interface).Member("y").From)
+}
+
+func TestMergePartialMixins(t *testing.T) {
+	f := parser.Parse(`interface mixin Body {
+	readonly attribute boolean bodyUsed;
+};
+partial interface mixin Body {
+	Promise<DOMString> text();
+};`)
+	m := ast.MergePartials(f, ast.MergeOptions{TagOrigin: true})
+	require.Len(t, m.Declarations, 1)
+	mixin := m.Declarations[0].(*ast.Mixin)
+	require.False(t, mixin.Partial)
+	require.Len(t, mixin.Members, 2)
+	text := mixin.Members[1].(*ast.Member)
+	require.Equal(t, "text", text.Name)
+	require.Equal(t, "partial interface mixin Body at line 4", text.From)
+}
+
+func TestMergePartialCollections(t *testing.T) {
+	f := parser.Parse(`[Exposed=Window]
+interface Foo {};
+[SecureContext]
+partial interface Foo {
+	maplike<long, long>;
+};
+interface Bar {
+	setlike<long>;
+};
+partial interface Bar {
+	iterable<long>;
+};
+dictionary Dict {};
+[Deprecated]
+partial dictionary Dict {};`)
+	m := ast.MergePartials(f, ast.MergeOptions{})
+	require.Len(t, m.Declarations, 3)
+
+	foo := m.Declarations[0].(*ast.Interface)
+	require.NotNil(t, foo.Maplike)
+	require.Equal(t, 5, foo.Maplike.Line)
+	require.Equal(t, []string{"Exposed", "SecureContext"}, annotationNames(foo.Annotations))
+	require.Empty(t, ast.AllErrors(foo))
+
+	bar := m.Declarations[1].(*ast.Interface)
+	require.NotNil(t, bar.Setlike)
+	require.Nil(t, bar.Iterable)
+	errs := ast.AllErrors(bar)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Interface Bar can only have one iterable, maplike or setlike declaration", errs[0].Message)
+	require.Equal(t, 11, errs[0].Line)
+
+	dict := m.Declarations[2].(*ast.Dictionary)
+	require.Equal(t, []string{"Deprecated"}, annotationNames(dict.Annotations))
+}
+
+func TestFileMerge(t *testing.T) {
+	f := parser.Parse(`interface Foo {
+	attribute long x;
+};
+dictionary Dict {};`)
+	other := parser.Parse(`partial interface Foo {
+	attribute long y;
+};
+interface Bar {};
+dictionary Dict {};`)
+	f.Merge(other)
+	require.Len(t, f.Declarations, 5)
+	// both dictionaries are kept
+	require.Equal(t, "Dict", f.Declarations[1].(*ast.Dictionary).Name)
+	require.Equal(t, "Dict", f.Declarations[4].(*ast.Dictionary).Name)
+
+	m := ast.MergePartials(f, ast.MergeOptions{})
+	require.Len(t, m.Declarations, 4)
+	require.Len(t, m.Declarations[0].(*ast.Interface).Members, 2)
+}
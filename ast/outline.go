@@ -0,0 +1,68 @@
+package ast
+
+// OutlineItem describes a single declaration in the file outline.
+type OutlineItem struct {
+	Name      string
+	Kind      string // declaration keyword, like "interface" or "dictionary"
+	StartLine int
+	EndLine   int
+	Node      Decl
+}
+
+// Outline returns a list of top-level declarations of the file with their line ranges.
+// It can be used to build a symbol tree in an editor.
+func (f *File) Outline() []OutlineItem {
+	out := make([]OutlineItem, 0, len(f.Declarations))
+	for _, d := range f.Declarations {
+		b := d.NodeBase()
+		out = append(out, OutlineItem{
+			Name:      outlineName(d),
+			Kind:      declKind(d),
+			StartLine: b.Line,
+			EndLine:   b.EndLine,
+			Node:      d,
+		})
+	}
+	return out
+}
+
+// outlineName returns the name of a declaration as shown in the outline.
+func outlineName(d Decl) string {
+	switch d := d.(type) {
+	case *Includes:
+		return d.Name + " includes " + d.Source
+	case *Implementation:
+		return d.Name + " implements " + d.Source
+	}
+	return declName(d)
+}
+
+// declKind returns the keyword used to define a declaration.
+func declKind(d Decl) string {
+	switch d := d.(type) {
+	case *Interface:
+		if d.Callback {
+			return "callback interface"
+		}
+		return "interface"
+	case *Mixin:
+		return "interface mixin"
+	case *Dictionary:
+		return "dictionary"
+	case *Namespace:
+		return "namespace"
+	case *Enum:
+		return "enum"
+	case *Typedef:
+		return "typedef"
+	case *Callback:
+		return "callback"
+	case *Includes:
+		return "includes"
+	case *Implementation:
+		return "implements"
+	case *CustomDecl:
+		return d.Keyword
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutline(t *testing.T) {
+	f := parser.Parse(`interface Foo {
+	attribute long x;
+};
+
+[Exposed=Window]
+interface Bar : Foo {
+	void f();
+	void g();
+};
+Bar includes Baz;`)
+	var got []ast.OutlineItem
+	for _, it := range f.Outline() {
+		it.Node = nil
+		got = append(got, it)
+	}
+	require.Equal(t, []ast.OutlineItem{
+		{Name: "Foo", Kind: "interface", StartLine: 1, EndLine: 3},
+		{Name: "Bar", Kind: "interface", StartLine: 5, EndLine: 9},
+		{Name: "Bar includes Baz", Kind: "includes", StartLine: 10, EndLine: 10},
+	}, got)
+}
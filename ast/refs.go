@@ -0,0 +1,114 @@
+package ast
+
+// Reference is a reference to a named declaration.
+type Reference struct {
+	Name string
+	Node Node // node containing the reference
+}
+
+// UndefinedReferences returns all references to names that are neither declared
+// in the file, nor built into WebIDL. This includes types of members and parameters,
+// inherited interfaces and sources of includes and implements statements.
+func (f *File) UndefinedReferences() []Reference {
+	defined := make(map[string]struct{})
+	for _, d := range f.Declarations {
+		if name := declName(d); name != "" {
+			defined[name] = struct{}{}
+		}
+	}
+	var out []Reference
+	check := func(name string, n Node) {
+		if name == "" || IsBuiltin(name) {
+			return
+		}
+		if _, ok := defined[name]; !ok {
+			out = append(out, Reference{Name: name, Node: n})
+		}
+	}
+	Walk(f, func(n Node) bool {
+		switch n := n.(type) {
+		case *TypeName:
+			check(n.Name, n)
+		case *ParametrizedType:
+			check(n.Name, n)
+		case *Interface:
+			check(n.Inherits, n)
+		case *Mixin:
+			check(n.Inherits, n)
+		case *Dictionary:
+			check(n.Inherits, n)
+		case *Includes:
+			check(n.Name, n)
+			check(n.Source, n)
+		case *Implementation:
+			check(n.Name, n)
+			check(n.Source, n)
+		}
+		return true
+	})
+	return out
+}
+
+// TypeReferences returns distinct names of types referenced in the subtree of a given node,
+// in order of their first occurrence. This includes types of members and parameters,
+// element types of generic types and inherited declarations. Types built into WebIDL are
+// not included.
+func TypeReferences(n Node) []string {
+	var (
+		out  []string
+		seen = make(map[string]struct{})
+	)
+	add := func(name string) {
+		if name == "" || IsBuiltin(name) {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	Walk(n, func(n Node) bool {
+		switch n := n.(type) {
+		case *TypeName:
+			add(n.Name)
+		case *ParametrizedType:
+			add(n.Name)
+		case *Interface:
+			add(n.Inherits)
+		case *Mixin:
+			add(n.Inherits)
+		case *Dictionary:
+			add(n.Inherits)
+		case *Includes:
+			add(n.Source)
+		case *Implementation:
+			add(n.Source)
+		}
+		return true
+	})
+	return out
+}
+
+// declName returns the name of a declaration that can be referenced by other declarations.
+func declName(d Decl) string {
+	switch d := d.(type) {
+	case *Interface:
+		return d.Name
+	case *Mixin:
+		return d.Name
+	case *Dictionary:
+		return d.Name
+	case *Namespace:
+		return d.Name
+	case *Enum:
+		return d.Name
+	case *Typedef:
+		return d.Name
+	case *Callback:
+		return d.Name
+	case *CustomDecl:
+		return d.Name
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndefinedReferences(t *testing.T) {
+	f := parser.Parse(`interface Foo : EventTarget {
+	attribute long x;
+	Promise<sequence<Bar>> f(FooOptions opts, DOMString s);
+};
+dictionary Bar {};
+Foo includes Mixed;
+`)
+	refs := f.UndefinedReferences()
+	var names []string
+	for _, r := range refs {
+		names = append(names, r.Name)
+	}
+	require.Equal(t, []string{"EventTarget", "FooOptions", "Mixed"}, names)
+
+	opts := refs[1].Node.(*ast.TypeName)
+	require.Equal(t, 3, opts.Line)
+}
+
+func TestTypeReferences(t *testing.T) {
+	f := parser.Parse(`interface Foo : EventTarget {
+	attribute FrozenArray<Node> children;
+	attribute sequence<(Element or Text)?> nodes;
+	Promise<Result> run(long n, Options opts, optional Node other);
+};`)
+	refs := ast.TypeReferences(f.Declarations[0])
+	require.Equal(t, []string{"EventTarget", "Node", "Element", "Text", "Result", "Options"}, refs)
+}
+
+func TestUndefinedReferencesSpecTypes(t *testing.T) {
+	// these types are declared by other specifications, not built into WebIDL
+	const decls = `interface A {
+	attribute DOMTimeStamp t;
+	void f(Function cb, VoidFunction done);
+};
+`
+	f := parser.Parse(decls)
+	var names []string
+	for _, r := range f.UndefinedReferences() {
+		names = append(names, r.Name)
+	}
+	require.Equal(t, []string{"DOMTimeStamp", "Function", "VoidFunction"}, names)
+
+	f = parser.Parse(decls + `typedef unsigned long long DOMTimeStamp;
+callback Function = any (any... arguments);
+callback VoidFunction = void ();`)
+	require.Empty(t, f.UndefinedReferences())
+}
@@ -0,0 +1,48 @@
+package ast
+
+// Rename renames the declaration with a given name and updates all references to it:
+// inheritance, sources and targets of includes and implements statements, and type names.
+// Only exact matches are renamed. Extended attributes are left unchanged.
+// It returns the number of updated sites.
+func Rename(f *File, oldName, newName string) int {
+	n := 0
+	rename := func(name *string) {
+		if *name == oldName {
+			*name = newName
+			n++
+		}
+	}
+	Walk(f, func(node Node) bool {
+		switch node := node.(type) {
+		case *Interface:
+			rename(&node.Name)
+			rename(&node.Inherits)
+		case *Mixin:
+			rename(&node.Name)
+			rename(&node.Inherits)
+		case *Dictionary:
+			rename(&node.Name)
+			rename(&node.Inherits)
+		case *Namespace:
+			rename(&node.Name)
+		case *Enum:
+			rename(&node.Name)
+		case *Typedef:
+			rename(&node.Name)
+		case *Callback:
+			rename(&node.Name)
+		case *CustomDecl:
+			rename(&node.Name)
+		case *Includes:
+			rename(&node.Name)
+			rename(&node.Source)
+		case *Implementation:
+			rename(&node.Name)
+			rename(&node.Source)
+		case *TypeName:
+			rename(&node.Name)
+		}
+		return true
+	})
+	return n
+}
@@ -0,0 +1,39 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRename(t *testing.T) {
+	f := parser.Parse(`interface Node {
+	attribute Node? parent;
+	attribute NodeList children;
+	void append(sequence<Node> nodes);
+};
+interface Element : Node {};
+partial interface Node {};
+Node includes Mixin;
+typedef (Node or DOMString) NodeOrString;`)
+	n := ast.Rename(f, "Node", "DOMNode")
+	require.Equal(t, 7, n)
+	var undefined []string
+	for _, r := range f.UndefinedReferences() {
+		undefined = append(undefined, r.Name)
+	}
+	require.Equal(t, []string{"NodeList", "Mixin"}, undefined)
+
+	iface := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "DOMNode", iface.Name)
+	parent := iface.Member("parent").Type.(*ast.NullableType).Type.(*ast.TypeName)
+	require.Equal(t, "DOMNode", parent.Name)
+	// a name with the same prefix is not renamed
+	require.Equal(t, "NodeList", iface.Member("children").Type.(*ast.TypeName).Name)
+	require.Equal(t, "DOMNode", f.Declarations[1].(*ast.Interface).Inherits)
+	require.Equal(t, "DOMNode", f.Declarations[3].(*ast.Includes).Name)
+
+	require.Equal(t, 0, ast.Rename(f, "Node", "Other"))
+}
@@ -0,0 +1,63 @@
+package ast
+
+// RewriteTypes walks the tree and replaces each type with the result of fn.
+// Types are rewritten bottom-up: element types of a composite type are replaced
+// before fn is called for the composite type itself.
+//
+// It can be used for transforms like replacing all DOMString types with USVString.
+// If n is a type itself, the result of fn for it is discarded.
+func RewriteTypes(n Node, fn func(Type) Type) {
+	rewrite := func(t *Type) {
+		if *t != nil {
+			*t = rewriteType(*t, fn)
+		}
+	}
+	Walk(n, func(n Node) bool {
+		switch n := n.(type) {
+		case Type:
+			// rewritten by the parent node
+			return false
+		case *Member:
+			rewrite(&n.Type)
+		case *Parameter:
+			rewrite(&n.Type)
+		case *Typedef:
+			rewrite(&n.Type)
+		case *Callback:
+			rewrite(&n.Return)
+		case *Iterable:
+			rewrite(&n.Key)
+			rewrite(&n.Elem)
+		case *Maplike:
+			rewrite(&n.Key)
+			rewrite(&n.Elem)
+		case *Setlike:
+			rewrite(&n.Elem)
+		}
+		return true
+	})
+	if t, ok := n.(Type); ok {
+		rewriteType(t, fn)
+	}
+}
+
+func rewriteType(t Type, fn func(Type) Type) Type {
+	switch t := t.(type) {
+	case *SequenceType:
+		t.Elem = rewriteType(t.Elem, fn)
+	case *RecordType:
+		t.Key = rewriteType(t.Key, fn)
+		t.Elem = rewriteType(t.Elem, fn)
+	case *ParametrizedType:
+		for i, e := range t.Elems {
+			t.Elems[i] = rewriteType(e, fn)
+		}
+	case *UnionType:
+		for i, e := range t.Types {
+			t.Types[i] = rewriteType(e, fn)
+		}
+	case *NullableType:
+		t.Type = rewriteType(t.Type, fn)
+	}
+	return fn(t)
+}
@@ -0,0 +1,46 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteTypes(t *testing.T) {
+	f := parser.MustParse(`interface Foo {
+	attribute DOMString name;
+	attribute DOMString? title;
+	record<DOMString, sequence<DOMString>> get((DOMString or long) key);
+	iterable<DOMString, long>;
+};
+typedef Promise<DOMString> P;`)
+	ast.RewriteTypes(f, func(t ast.Type) ast.Type {
+		if tn, ok := t.(*ast.TypeName); ok && tn.Name == "DOMString" {
+			tn.Name = "USVString"
+		}
+		return t
+	})
+	var names []string
+	ast.Walk(f, func(n ast.Node) bool {
+		if tn, ok := n.(*ast.TypeName); ok {
+			names = append(names, tn.Name)
+		}
+		return true
+	})
+	require.Equal(t, []string{
+		"USVString", "USVString", "USVString", "USVString",
+		"USVString", "long", "USVString", "long", "USVString",
+	}, names)
+
+	// unwrap nullable types
+	ast.RewriteTypes(f, func(t ast.Type) ast.Type {
+		if nt, ok := t.(*ast.NullableType); ok {
+			return nt.Type
+		}
+		return t
+	})
+	iface := f.Declarations[0].(*ast.Interface)
+	require.IsType(t, &ast.TypeName{}, iface.Member("title").Type)
+}
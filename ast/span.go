@@ -0,0 +1,18 @@
+package ast
+
+// Span is a range of byte offsets in the source. Both ends are inclusive,
+// like the Start and End fields of Base.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Span returns the range of the source covered by the node.
+func (b *Base) Span() Span {
+	return Span{Start: b.Start, End: b.End}
+}
+
+// Contains checks if the offset is within the span.
+func (s Span) Contains(pos int) bool {
+	return s.Start <= pos && pos <= s.End
+}
@@ -0,0 +1,29 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpanContains(t *testing.T) {
+	const src = `interface Foo {
+	attribute long x;
+};`
+	f := parser.MustParse(src)
+	s := f.Declarations[0].NodeBase().Span()
+	require.Equal(t, 0, s.Start)
+	require.Equal(t, len(src)-1, s.End)
+	require.True(t, s.Contains(0))
+	require.True(t, s.Contains(len(src)-1))
+	require.False(t, s.Contains(len(src)))
+	require.False(t, s.Contains(-1))
+
+	m := f.Declarations[0].(*ast.Interface).Member("x").Span()
+	require.True(t, m.Contains(strings.Index(src, "attribute")))
+	require.True(t, m.Contains(strings.Index(src, " x;")+1))
+	require.False(t, m.Contains(strings.Index(src, "{")))
+}
@@ -0,0 +1,90 @@
+package ast
+
+import "fmt"
+
+// TopoSort orders declarations of the file so that each declaration appears after the
+// declarations it depends on: inherited declarations, sources of includes statements and
+// types referenced by members and parameters.
+//
+// Cycles of type references are legal in WebIDL (an interface may have an attribute of
+// its own type, or two interfaces may refer to each other), so they are broken
+// deterministically: declarations are visited in source order and a dependency that is
+// already being visited is skipped. Partial declarations with the same name are kept in
+// source order relative to each other. An error is returned only for inheritance cycles,
+// which are invalid.
+func TopoSort(f *File) ([]Decl, error) {
+	var (
+		byName   = make(map[string][]Decl)
+		inherits = make(map[string]string)
+	)
+	for _, d := range f.Declarations {
+		if name := declName(d); name != "" {
+			byName[name] = append(byName[name], d)
+		}
+		var name, parent string
+		switch d := d.(type) {
+		case *Interface:
+			name, parent = d.Name, d.Inherits
+		case *Mixin:
+			name, parent = d.Name, d.Inherits
+		case *Dictionary:
+			name, parent = d.Name, d.Inherits
+		}
+		if parent != "" && inherits[name] == "" {
+			inherits[name] = parent
+		}
+	}
+	for _, d := range f.Declarations {
+		name := declName(d)
+		if inherits[name] == "" {
+			continue
+		}
+		seen := map[string]bool{name: true}
+		for cur := inherits[name]; cur != ""; cur = inherits[cur] {
+			if cur == name {
+				return nil, fmt.Errorf("inheritance cycle found for %q", name)
+			} else if seen[cur] {
+				// cycle that doesn't involve this name; reported when checking its members
+				break
+			}
+			seen[cur] = true
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	var (
+		out   = make([]Decl, 0, len(f.Declarations))
+		state = make(map[Decl]int)
+	)
+	var visit func(d Decl)
+	visit = func(d Decl) {
+		if state[d] != 0 {
+			return
+		}
+		state[d] = visiting
+		name := declName(d)
+		for _, ref := range TypeReferences(d) {
+			if ref == name {
+				continue
+			}
+			for _, dep := range byName[ref] {
+				visit(dep)
+			}
+		}
+		if d, ok := d.(*Includes); ok {
+			// includes statement also depends on the target interface
+			for _, dep := range byName[d.Name] {
+				visit(dep)
+			}
+		}
+		state[d] = done
+		out = append(out, d)
+	}
+	for _, d := range f.Declarations {
+		visit(d)
+	}
+	return out, nil
+}
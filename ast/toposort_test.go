@@ -0,0 +1,41 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopoSort(t *testing.T) {
+	f := parser.Parse(`interface Window : EventTarget {
+	attribute Document document;
+	void open(OpenOptions opts);
+};
+interface Document {
+	readonly attribute Window? defaultView;
+};
+dictionary OpenOptions {
+	Mode mode;
+};
+enum Mode { "a", "b" };
+interface EventTarget {};`)
+	sorted, err := ast.TopoSort(f)
+	require.NoError(t, err)
+	var names []string
+	for _, it := range (&ast.File{Declarations: sorted}).Outline() {
+		names = append(names, it.Name)
+	}
+	// Window and Document refer to each other, the cycle is broken in source order
+	require.Equal(t, []string{"EventTarget", "Document", "Mode", "OpenOptions", "Window"}, names)
+
+	// the first declaration of the cycle in source order is reported
+	f = parser.Parse(`interface C : A {};
+interface B : C {};
+interface A : B {};`)
+	for i := 0; i < 10; i++ {
+		_, err = ast.TopoSort(f)
+		require.EqualError(t, err, `inheritance cycle found for "C"`)
+	}
+}
@@ -0,0 +1,165 @@
+package ast
+
+import "strings"
+
+// IsString checks if the type is one of the built-in string types:
+// DOMString, USVString, ByteString or CSSOMString.
+// The exact name is still available in the Name field.
+func (t *TypeName) IsString() bool {
+	return t.Kind() == KindString
+}
+
+// Annotation returns the first extended attribute of the type with a given name, or nil.
+func (t *TypeName) Annotation(name string) *Annotation {
+	return findAnnotation(t.Annotations, name)
+}
+
+// Unwrap returns the inner type of a nullable type and true,
+// or the type itself and false if it's not nullable.
+func Unwrap(t Type) (inner Type, nullable bool) {
+	if nt, ok := t.(*NullableType); ok {
+		return nt.Type, true
+	}
+	return t, false
+}
+
+// Flatten returns the flattened member types of the union: nested unions are expanded
+// and nullable types are replaced by their inner types. Duplicate types are removed.
+func (u *UnionType) Flatten() []Type {
+	var (
+		out  []Type
+		seen = make(map[string]struct{})
+	)
+	var flatten func(types []Type)
+	flatten = func(types []Type) {
+		for _, t := range types {
+			if nt, ok := t.(*NullableType); ok {
+				t = nt.Type
+			}
+			if ut, ok := t.(*UnionType); ok {
+				flatten(ut.Types)
+				continue
+			}
+			key := typeString(t)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out = append(out, t)
+		}
+	}
+	flatten(u.Types)
+	return out
+}
+
+// Contains checks if the union has a member type with a given name.
+// The name can either be a type name (like "sequence") or a full type spelling (like "sequence<long>").
+func (u *UnionType) Contains(name string) bool {
+	for _, t := range u.Flatten() {
+		switch t := t.(type) {
+		case *TypeName:
+			if t.Name == name {
+				return true
+			}
+		case *ParametrizedType:
+			if t.Name == name {
+				return true
+			}
+		case *SequenceType:
+			if name == "sequence" {
+				return true
+			}
+		case *RecordType:
+			if name == "record" {
+				return true
+			}
+		}
+		if typeString(t) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TypesEqual checks if two types are structurally equal, ignoring positions and
+// extended attributes. Member types of unions are compared regardless of their order.
+func TypesEqual(a, b Type) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch a := a.(type) {
+	case *TypeName:
+		b, ok := b.(*TypeName)
+		return ok && a.Name == b.Name
+	case *AnyType:
+		_, ok := b.(*AnyType)
+		return ok
+	case *SequenceType:
+		b, ok := b.(*SequenceType)
+		return ok && TypesEqual(a.Elem, b.Elem)
+	case *RecordType:
+		b, ok := b.(*RecordType)
+		return ok && TypesEqual(a.Key, b.Key) && TypesEqual(a.Elem, b.Elem)
+	case *ParametrizedType:
+		b, ok := b.(*ParametrizedType)
+		if !ok || a.Name != b.Name || len(a.Elems) != len(b.Elems) {
+			return false
+		}
+		for i := range a.Elems {
+			if !TypesEqual(a.Elems[i], b.Elems[i]) {
+				return false
+			}
+		}
+		return true
+	case *UnionType:
+		b, ok := b.(*UnionType)
+		if !ok || len(a.Types) != len(b.Types) {
+			return false
+		}
+		used := make([]bool, len(b.Types))
+	next:
+		for _, at := range a.Types {
+			for j, bt := range b.Types {
+				if !used[j] && TypesEqual(at, bt) {
+					used[j] = true
+					continue next
+				}
+			}
+			return false
+		}
+		return true
+	case *NullableType:
+		b, ok := b.(*NullableType)
+		return ok && TypesEqual(a.Type, b.Type)
+	}
+	return false
+}
+
+// typeString returns the IDL spelling of the type.
+func typeString(t Type) string {
+	switch t := t.(type) {
+	case *TypeName:
+		return t.Name
+	case *AnyType:
+		return "any"
+	case *SequenceType:
+		return "sequence<" + typeString(t.Elem) + ">"
+	case *RecordType:
+		return "record<" + typeString(t.Key) + ", " + typeString(t.Elem) + ">"
+	case *ParametrizedType:
+		elems := make([]string, 0, len(t.Elems))
+		for _, e := range t.Elems {
+			elems = append(elems, typeString(e))
+		}
+		return t.Name + "<" + strings.Join(elems, ", ") + ">"
+	case *UnionType:
+		types := make([]string, 0, len(t.Types))
+		for _, e := range t.Types {
+			types = append(types, typeString(e))
+		}
+		return "(" + strings.Join(types, " or ") + ")"
+	case *NullableType:
+		return typeString(t.Type) + "?"
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+// parseTypedef parses a single typedef and returns its type.
+func parseTypedef(t *testing.T, src string) ast.Type {
+	f := parser.Parse(src)
+	require.Len(t, f.Declarations, 1)
+	td, ok := f.Declarations[0].(*ast.Typedef)
+	require.True(t, ok)
+	return td.Type
+}
+
+func TestUnionFlatten(t *testing.T) {
+	u := parseTypedef(t, `typedef (long or DOMString) Simple;`).(*ast.UnionType)
+	flat := u.Flatten()
+	require.Len(t, flat, 2)
+	require.True(t, u.Contains("long"))
+	require.True(t, u.Contains("DOMString"))
+	require.False(t, u.Contains("Node"))
+
+	u = parseTypedef(t, `typedef ((A or B)? or (sequence<long> or A) or C) Nested;`).(*ast.UnionType)
+	flat = u.Flatten()
+	require.Len(t, flat, 4)
+	require.Equal(t, "A", flat[0].(*ast.TypeName).Name)
+	require.Equal(t, "B", flat[1].(*ast.TypeName).Name)
+	require.IsType(t, &ast.SequenceType{}, flat[2])
+	require.Equal(t, "C", flat[3].(*ast.TypeName).Name)
+	require.True(t, u.Contains("sequence"))
+	require.True(t, u.Contains("sequence<long>"))
+	require.True(t, u.Contains("C"))
+	require.False(t, u.Contains("long"))
+}
+
+func TestUnwrap(t *testing.T) {
+	typ := parseTypedef(t, `typedef sequence<long>? Nullable;`)
+	inner, nullable := ast.Unwrap(typ)
+	require.True(t, nullable)
+	require.IsType(t, &ast.SequenceType{}, inner)
+
+	typ = parseTypedef(t, `typedef DOMString Str;`)
+	inner, nullable = ast.Unwrap(typ)
+	require.False(t, nullable)
+	require.True(t, inner == typ)
+}
+
+func TestTypeNameIsString(t *testing.T) {
+	for _, name := range []string{"DOMString", "USVString", "ByteString", "CSSOMString"} {
+		typ := parseTypedef(t, `typedef `+name+` Str;`).(*ast.TypeName)
+		require.Equal(t, name, typ.Name)
+		require.True(t, typ.IsString(), name)
+	}
+	for _, name := range []string{"long", "Node", "object"} {
+		typ := parseTypedef(t, `typedef `+name+` NotStr;`).(*ast.TypeName)
+		require.False(t, typ.IsString(), name)
+	}
+}
+
+func TestTypesEqual(t *testing.T) {
+	parse := func(typ string) ast.Type {
+		return parseTypedef(t, `typedef `+typ+` T;`)
+	}
+	cases := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"long", "long", true},
+		{"long", "unsigned long", false},
+		{"any", "any", true},
+		{"sequence<long>", "sequence<long>", true},
+		{"sequence<long>", "sequence<short>", false},
+		{"sequence<long>", "FrozenArray<long>", false},
+		{"record<DOMString, long>", "record<DOMString, long>", true},
+		{"record<DOMString, long>", "record<USVString, long>", false},
+		{"Promise<undefined>", "Promise<undefined>", true},
+		{"Promise<undefined>", "Promise<long>", false},
+		{"long?", "long?", true},
+		{"long?", "long", false},
+		{"(long or DOMString)", "(DOMString or long)", true},
+		{"(long or DOMString)", "(long or DOMString or Node)", false},
+		{"(long or long)", "(long or DOMString)", false},
+		{"(sequence<long> or Node)?", "(Node or sequence<long>)?", true},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.equal, ast.TypesEqual(parse(c.a), parse(c.b)), c.a+" vs "+c.b)
+		require.Equal(t, c.equal, ast.TypesEqual(parse(c.b), parse(c.a)), c.b+" vs "+c.a)
+	}
+}
+
+func TestTypeKind(t *testing.T) {
+	f := parser.Parse(`interface Foo {
+	attribute Date created;
+	attribute unsigned long long size;
+	attribute USVString url;
+	attribute Uint8Array data;
+	attribute Node parent;
+};`)
+	iface := f.Declarations[0].(*ast.Interface)
+	kind := func(name string) ast.TypeKind {
+		return iface.Member(name).Type.(*ast.TypeName).Kind()
+	}
+	require.Equal(t, ast.KindDate, kind("created"))
+	require.Equal(t, "date", kind("created").String())
+	require.True(t, ast.IsBuiltin("Date"))
+	require.True(t, ast.IsBuiltin("Promise"))
+	require.False(t, ast.IsBuiltin("Function"))
+	require.Equal(t, ast.KindInteger, kind("size"))
+	require.Equal(t, ast.KindString, kind("url"))
+	require.Equal(t, ast.KindBuffer, kind("data"))
+	require.Equal(t, ast.KindReference, kind("parent"))
+}
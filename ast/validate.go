@@ -0,0 +1,247 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate runs all checks on the file and returns the problems found, sorted by position.
+// It includes errors reported by the parser (like illegal modifiers or the order of
+// arguments), references to undefined names, duplicate dictionary members, incompatible
+// default values and attributes of Promise types. Warnings are not included.
+//
+// All returned errors are of the *ErrorNode type.
+func (f *File) Validate() []error {
+	list := AllErrors(f)
+	for _, r := range f.UndefinedReferences() {
+		list = append(list, nodeError(r.Node, "Undefined reference to %s", r.Name))
+	}
+	list = append(list, ValidateDictionaryMembers(f)...)
+	list = append(list, ValidateDefaults(f)...)
+	list = append(list, ValidatePromiseAttributes(f)...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Start < list[j].Start
+	})
+	type errorKey struct {
+		start   int
+		message string
+	}
+	var (
+		out  []error
+		seen = make(map[errorKey]struct{})
+	)
+	for _, e := range list {
+		key := errorKey{e.Start, e.Message}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, e)
+	}
+	return out
+}
+
+// ValidateDefaults checks that default values of dictionary members, constants and
+// optional parameters are compatible with their types: numbers for numeric types,
+// strings for string and enum types, {} for dictionaries, [] for sequences and
+// null only for nullable types. Types that are not declared in the file are not checked.
+//
+// Errors are positioned at the default value and sorted by position.
+func ValidateDefaults(f *File) []*ErrorNode {
+	v := &defaultsValidator{decls: make(map[string]Decl)}
+	for _, d := range f.Declarations {
+		switch d.(type) {
+		case *Enum, *Dictionary, *Typedef, *Interface, *Callback:
+			if name := declName(d); name != "" && v.decls[name] == nil {
+				v.decls[name] = d
+			}
+		}
+	}
+	var out []*ErrorNode
+	check := func(t Type, init Literal) {
+		if t == nil || init == nil || v.compatible(t, init, 0) {
+			return
+		}
+		out = append(out, nodeError(init, "Default value %s is not compatible with type %s", literalString(init), typeString(t)))
+	}
+	Walk(f, func(n Node) bool {
+		switch n := n.(type) {
+		case *Member:
+			check(n.Type, n.Init)
+		case *Parameter:
+			check(n.Type, n.Init)
+		}
+		return true
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start < out[j].Start
+	})
+	return out
+}
+
+// ValidatePromiseAttributes checks that attributes are not of a Promise type.
+// Only operations can return promises.
+func ValidatePromiseAttributes(f *File) []*ErrorNode {
+	var out []*ErrorNode
+	Walk(f, func(n Node) bool {
+		m, ok := n.(*Member)
+		if !ok || !m.Attribute || m.Type == nil {
+			return true
+		}
+		t, _ := Unwrap(m.Type)
+		if pt, ok := t.(*ParametrizedType); ok && pt.Name == "Promise" {
+			out = append(out, nodeError(m, "Attribute %s cannot be of a Promise type", m.Name))
+		}
+		return true
+	})
+	return out
+}
+
+// ValidateDictionaryMembers checks that members of a dictionary have unique names,
+// including members of partial dictionaries with the same name.
+// Errors are reported for every repeated occurrence of the name.
+func ValidateDictionaryMembers(f *File) []*ErrorNode {
+	var out []*ErrorNode
+	seen := make(map[string]map[string]struct{})
+	for _, d := range f.Declarations {
+		d, ok := d.(*Dictionary)
+		if !ok {
+			continue
+		}
+		names := seen[d.Name]
+		if names == nil {
+			names = make(map[string]struct{})
+			seen[d.Name] = names
+		}
+		for _, m := range d.Members {
+			if _, ok := names[m.Name]; ok {
+				out = append(out, nodeError(m, "Duplicate member %s in dictionary %s", m.Name, d.Name))
+				continue
+			}
+			names[m.Name] = struct{}{}
+		}
+	}
+	return out
+}
+
+// nodeError creates an error spanning the node.
+func nodeError(n Node, format string, args ...interface{}) *ErrorNode {
+	b := n.NodeBase()
+	e := &ErrorNode{Message: fmt.Sprintf(format, args...)}
+	e.Start, e.End = b.Start, b.End
+	e.Line, e.EndLine = b.Line, b.EndLine
+	return e
+}
+
+type defaultsValidator struct {
+	decls map[string]Decl
+}
+
+// compatible checks if the literal can be used as a default value for the type.
+// It returns true if the type cannot be checked.
+func (v *defaultsValidator) compatible(t Type, l Literal, depth int) bool {
+	if depth > len(v.decls) {
+		// typedef cycle; reported elsewhere
+		return true
+	}
+	switch t := t.(type) {
+	case *NullableType:
+		if _, ok := l.(*NullLiteral); ok {
+			return true
+		}
+		return v.compatible(t.Type, l, depth+1)
+	case *AnyType:
+		return true
+	case *SequenceType:
+		_, ok := l.(*SequenceLiteral)
+		return ok
+	case *ParametrizedType:
+		if t.Name == "FrozenArray" || t.Name == "ObservableArray" {
+			_, ok := l.(*SequenceLiteral)
+			return ok
+		}
+		return true
+	case *UnionType:
+		// a union with a nullable member type is nullable as well,
+		// so null is checked by the member types
+		for _, e := range t.Types {
+			if v.compatible(e, l, depth+1) {
+				return true
+			}
+		}
+		return false
+	case *TypeName:
+		switch t.Kind() {
+		case KindAny:
+			return true
+		case KindBoolean:
+			_, ok := l.(*BoolLiteral)
+			return ok
+		case KindInteger, KindBigInt:
+			return isNumberLiteral(l, false)
+		case KindFloat:
+			return isNumberLiteral(l, strings.HasPrefix(t.Name, "unrestricted"))
+		case KindString:
+			return isStringLiteral(l)
+		case KindReference:
+			switch d := v.decls[t.Name].(type) {
+			case *Enum:
+				return isStringLiteral(l)
+			case *Dictionary:
+				_, ok := l.(*DictionaryLiteral)
+				return ok
+			case *Typedef:
+				return v.compatible(d.Type, l, depth+1)
+			case *Interface, *Callback:
+				// only null is allowed, and it's checked for nullable types above
+				return false
+			}
+			return true
+		}
+		return true
+	}
+	return true
+}
+
+// isNumberLiteral checks if the literal is a number. Infinity and NaN are only allowed
+// for unrestricted floating point types.
+func isNumberLiteral(l Literal, unrestricted bool) bool {
+	b, ok := l.(*BasicLiteral)
+	if !ok || b.Value == "" {
+		return false
+	}
+	switch b.Value {
+	case "Infinity", "-Infinity", "NaN":
+		return unrestricted
+	}
+	c := b.Value[0]
+	return c == '-' || c == '.' || (c >= '0' && c <= '9')
+}
+
+// isStringLiteral checks if the literal is a quoted string.
+func isStringLiteral(l Literal) bool {
+	b, ok := l.(*BasicLiteral)
+	return ok && strings.HasPrefix(b.Value, `"`)
+}
+
+// literalString returns the IDL spelling of the literal.
+func literalString(l Literal) string {
+	switch l := l.(type) {
+	case *BasicLiteral:
+		return l.Value
+	case *BoolLiteral:
+		return l.String()
+	case *NullLiteral:
+		return "null"
+	case *DictionaryLiteral:
+		return "{}"
+	case *SequenceLiteral:
+		elems := make([]string, 0, len(l.Elems))
+		for _, e := range l.Elems {
+			elems = append(elems, literalString(e))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return ""
+}
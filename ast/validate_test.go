@@ -0,0 +1,130 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+// errorMessages returns messages of error nodes.
+func errorMessages(list []*ast.ErrorNode) []string {
+	var out []string
+	for _, e := range list {
+		out = append(out, e.Message)
+	}
+	return out
+}
+
+func TestValidateDefaults(t *testing.T) {
+	f := parser.MustParse(`enum Mode { "a", "b" };
+dictionary Options {
+	long x = 1;
+	unrestricted double y = NaN;
+	DOMString s = "str";
+	boolean b = false;
+	Mode mode = "a";
+	sequence<long> list = [];
+	Options? next = null;
+	Inner inner = {};
+	(long or DOMString) u = "u";
+	Unknown z = 0;
+};
+dictionary Inner {};
+interface Foo {
+	const short C = -1;
+	void f(optional Options opts = {}, optional long n = 0);
+};`)
+	require.Empty(t, ast.ValidateDefaults(f))
+
+	const src = `dictionary Options {
+	long x = "hello";
+	double y = Infinity;
+	DOMString s = 1;
+	Inner inner = null;
+	sequence<long> list = {};
+};
+dictionary Inner {};
+interface Foo {
+	void f(optional boolean b = 0);
+};`
+	f = parser.MustParse(src)
+	errs := ast.ValidateDefaults(f)
+	require.Equal(t, []string{
+		`Default value "hello" is not compatible with type long`,
+		`Default value Infinity is not compatible with type double`,
+		`Default value 1 is not compatible with type DOMString`,
+		`Default value null is not compatible with type Inner`,
+		`Default value {} is not compatible with type sequence<long>`,
+		`Default value 0 is not compatible with type boolean`,
+	}, errorMessages(errs))
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, `"hello"`, src[errs[0].Start:errs[0].End+1])
+}
+
+func TestValidatePromiseAttributes(t *testing.T) {
+	f := parser.MustParse(`interface Foo {
+	readonly attribute Promise<long> ready;
+	readonly attribute long size;
+	Promise<undefined> close();
+};`)
+	errs := ast.ValidatePromiseAttributes(f)
+	require.Equal(t, []string{"Attribute ready cannot be of a Promise type"}, errorMessages(errs))
+	require.Equal(t, 2, errs[0].Line)
+}
+
+func TestValidateDictionaryMembers(t *testing.T) {
+	f := parser.MustParse(`dictionary Options {
+	long x;
+	DOMString y;
+	boolean x;
+};
+partial dictionary Options {
+	long y;
+	long z;
+};
+dictionary Other {
+	long x;
+};`)
+	errs := ast.ValidateDictionaryMembers(f)
+	require.Equal(t, []string{
+		"Duplicate member x in dictionary Options",
+		"Duplicate member y in dictionary Options",
+	}, errorMessages(errs))
+	require.Equal(t, 4, errs[0].Line)
+	require.Equal(t, 7, errs[1].Line)
+
+	// the same errors after partials are merged
+	merged := ast.MergePartials(f, ast.MergeOptions{})
+	require.Equal(t, errorMessages(errs), errorMessages(ast.ValidateDictionaryMembers(merged)))
+}
+
+func TestFileValidate(t *testing.T) {
+	f := parser.Parse(`dictionary Options {
+	long x = "str";
+	boolean x;
+};
+interface Foo {
+	readonly attribute Promise<long> ready;
+	void f(optional long a, long b);
+	attribute Unknown u;
+};
+Foo includes Missing;`)
+	var msgs []string
+	for _, err := range f.Validate() {
+		_, ok := err.(*ast.ErrorNode)
+		require.True(t, ok)
+		msgs = append(msgs, err.Error())
+	}
+	require.Equal(t, []string{
+		`2: Default value "str" is not compatible with type long`,
+		"3: Duplicate member x in dictionary Options",
+		"6: Attribute ready cannot be of a Promise type",
+		"7: Required parameter b cannot follow an optional parameter",
+		"8: Undefined reference to Unknown",
+		"10: Undefined reference to Missing",
+	}, msgs)
+
+	require.Empty(t, parser.MustParse(`interface Foo { attribute long x; };`).Validate())
+}
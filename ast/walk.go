@@ -0,0 +1,126 @@
+package ast
+
+// Walk traverses the tree in depth-first order, starting from a given node.
+// It calls fn for each node; if fn returns false, children of that node are skipped.
+func Walk(n Node, fn func(Node) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	switch n := n.(type) {
+	case *File:
+		for _, d := range n.Declarations {
+			Walk(d, fn)
+		}
+	case *Interface:
+		walkAnnotations(n.Annotations, fn)
+		for _, m := range n.Members {
+			Walk(m, fn)
+		}
+		for _, op := range n.CustomOps {
+			Walk(op, fn)
+		}
+		if n.Iterable != nil {
+			Walk(n.Iterable, fn)
+		}
+		if n.Maplike != nil {
+			Walk(n.Maplike, fn)
+		}
+		if n.Setlike != nil {
+			Walk(n.Setlike, fn)
+		}
+	case *Mixin:
+		walkAnnotations(n.Annotations, fn)
+		for _, m := range n.Members {
+			Walk(m, fn)
+		}
+		for _, op := range n.CustomOps {
+			Walk(op, fn)
+		}
+		if n.Iterable != nil {
+			Walk(n.Iterable, fn)
+		}
+	case *Dictionary:
+		walkAnnotations(n.Annotations, fn)
+		for _, m := range n.Members {
+			Walk(m, fn)
+		}
+	case *Namespace:
+		walkAnnotations(n.Annotations, fn)
+		for _, m := range n.Members {
+			Walk(m, fn)
+		}
+	case *Annotation:
+		walkParameters(n.Parameters, fn)
+	case *Parameter:
+		walkAnnotations(n.Annotations, fn)
+		Walk(n.Type, fn)
+		Walk(n.Init, fn)
+	case *Member:
+		walkAnnotations(n.Annotations, fn)
+		Walk(n.Type, fn)
+		walkParameters(n.Parameters, fn)
+		Walk(n.Init, fn)
+	case *Constructor:
+		walkAnnotations(n.Annotations, fn)
+		walkParameters(n.Parameters, fn)
+	case *Iterable:
+		Walk(n.Key, fn)
+		Walk(n.Elem, fn)
+		walkParameters(n.Parameters, fn)
+	case *Maplike:
+		Walk(n.Key, fn)
+		Walk(n.Elem, fn)
+	case *Setlike:
+		Walk(n.Elem, fn)
+	case *Callback:
+		walkAnnotations(n.Annotations, fn)
+		Walk(n.Return, fn)
+		walkParameters(n.Parameters, fn)
+	case *Enum:
+		walkAnnotations(n.Annotations, fn)
+		for _, v := range n.Values {
+			Walk(v, fn)
+		}
+	case *EnumValue:
+		walkAnnotations(n.Annotations, fn)
+		Walk(n.Value, fn)
+	case *Typedef:
+		walkAnnotations(n.Annotations, fn)
+		Walk(n.Type, fn)
+	case *CustomDecl:
+		walkAnnotations(n.Annotations, fn)
+	case *TypeName:
+		walkAnnotations(n.Annotations, fn)
+	case *SequenceType:
+		Walk(n.Elem, fn)
+	case *RecordType:
+		Walk(n.Key, fn)
+		Walk(n.Elem, fn)
+	case *ParametrizedType:
+		for _, t := range n.Elems {
+			Walk(t, fn)
+		}
+	case *UnionType:
+		for _, t := range n.Types {
+			Walk(t, fn)
+		}
+	case *NullableType:
+		Walk(n.Type, fn)
+	case *SequenceLiteral:
+		for _, e := range n.Elems {
+			Walk(e, fn)
+		}
+	}
+}
+
+func walkAnnotations(list []*Annotation, fn func(Node) bool) {
+	for _, a := range list {
+		Walk(a, fn)
+	}
+}
+
+func walkParameters(list []*Parameter, fn func(Node) bool) {
+	for _, p := range list {
+		Walk(p, fn)
+	}
+}
@@ -1,3 +1,8 @@
 module github.com/dennwc/webidl
 
 go 1.12
+
+require (
+	github.com/kr/pretty v0.3.1
+	github.com/stretchr/testify v1.12.1
+)
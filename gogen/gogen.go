@@ -0,0 +1,392 @@
+// Package gogen generates Go type declarations from WebIDL.
+//
+// Only dictionaries, enums and typedefs are supported; other declarations are skipped.
+// References to interfaces and callbacks are generated as interface{}, since they have
+// no data representation. Partial dictionaries must be merged with ast.MergePartials first.
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dennwc/webidl/ast"
+)
+
+// Mapping maps a range of lines in the generated code to the IDL node it was generated from.
+type Mapping struct {
+	StartLine int // first line of the generated code, 1-based
+	EndLine   int // last line of the generated code, inclusive
+	Node      ast.Node
+}
+
+// SourceMap maps generated code back to IDL positions.
+type SourceMap []Mapping
+
+// Lookup returns the innermost IDL node the given line of generated code was produced from, or nil.
+func (m SourceMap) Lookup(line int) ast.Node {
+	var (
+		best ast.Node
+		size int
+	)
+	for _, e := range m {
+		if line < e.StartLine || line > e.EndLine {
+			continue
+		}
+		if sz := e.EndLine - e.StartLine; best == nil || sz <= size {
+			best, size = e.Node, sz
+		}
+	}
+	return best
+}
+
+// GenerateGo generates Go declarations for the types defined in the file.
+func GenerateGo(f *ast.File, pkg string) ([]byte, error) {
+	data, _, err := GenerateGoWithMap(f, pkg)
+	return data, err
+}
+
+// GenerateGoWithMap is like GenerateGo, but also returns a mapping from the generated
+// code to IDL nodes.
+//
+// Names of fields and enum constants that clash after conversion to Go get a numeric suffix.
+// Types that clash, or references to types that are not declared in the file, are errors.
+func GenerateGoWithMap(f *ast.File, pkg string) ([]byte, SourceMap, error) {
+	g := &generator{
+		decls:    make(map[string]ast.Decl),
+		typedefs: make(map[string]ast.Type),
+		names:    make(map[string]bool),
+		types:    make(map[string]ast.Node),
+		fields:   make(map[string]ast.Node),
+		consts:   make(map[string]enumValue),
+	}
+	for _, d := range f.Declarations {
+		g.declare(d)
+	}
+	g.printf("// Code generated by webidl/gogen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n", pkg)
+	for _, d := range f.Declarations {
+		switch d := d.(type) {
+		case *ast.Dictionary:
+			g.dictionary(d)
+		case *ast.Enum:
+			g.enum(d)
+		case *ast.Typedef:
+			g.typedef(d)
+		}
+	}
+	if g.err != nil {
+		return nil, nil, g.err
+	}
+	data, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	smap, err := g.sourceMap(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, smap, nil
+}
+
+type generator struct {
+	buf      bytes.Buffer
+	err      error               // first error found
+	decls    map[string]ast.Decl // by IDL name
+	typedefs map[string]ast.Type
+	names    map[string]bool // Go names declared in the package
+
+	// IDL nodes of generated Go declarations, used to build the source map
+	types  map[string]ast.Node  // by type name
+	fields map[string]ast.Node  // by type and field name, separated by a dot
+	consts map[string]enumValue // by constant name
+}
+
+// enumValue is an IDL enum value and the enum it belongs to.
+type enumValue struct {
+	enum  *ast.Enum
+	value *ast.EnumValue
+}
+
+// errorf records an error, if there's none yet.
+func (g *generator) errorf(format string, args ...interface{}) {
+	if g.err == nil {
+		g.err = fmt.Errorf(format, args...)
+	}
+}
+
+// declare records a declaration that can be referenced by IDL types, and reserves
+// the name of the Go type generated for it.
+func (g *generator) declare(d ast.Decl) {
+	var name string
+	switch d := d.(type) {
+	case *ast.Dictionary:
+		if d.Partial {
+			g.errorf("partial dictionary %s must be merged first", d.Name)
+			return
+		}
+		name = d.Name
+	case *ast.Enum:
+		name = d.Name
+	case *ast.Typedef:
+		name = d.Name
+		g.typedefs[d.Name] = d.Type
+	case *ast.Interface:
+		g.decls[d.Name] = d
+		return
+	case *ast.Callback:
+		g.decls[d.Name] = d
+		return
+	default:
+		return
+	}
+	g.decls[name] = d
+	goName := exportedName(name)
+	if g.names[goName] {
+		g.errorf("declaration %s clashes with another declaration as Go type %s", name, goName)
+		return
+	}
+	g.names[goName] = true
+}
+
+// resolve follows typedefs to the underlying type.
+func (g *generator) resolve(t ast.Type) ast.Type {
+	for i := 0; i < len(g.typedefs); i++ {
+		tn, ok := t.(*ast.TypeName)
+		if !ok {
+			break
+		}
+		def, ok := g.typedefs[tn.Name]
+		if !ok {
+			break
+		}
+		t = def
+	}
+	return t
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) dictionary(d *ast.Dictionary) {
+	name := exportedName(d.Name)
+	g.types[name] = d
+	g.printf("\ntype %s struct {\n", name)
+	used := make(map[string]bool)
+	if d.Inherits != "" {
+		if _, ok := g.decls[d.Inherits].(*ast.Dictionary); !ok {
+			g.errorf("dictionary %s inherits from %s, which is not a dictionary declared in the file", d.Name, d.Inherits)
+		}
+		base := exportedName(d.Inherits)
+		used[base] = true
+		g.printf("\t%s\n", base)
+	}
+	for _, m := range d.Members {
+		field := uniqueName(exportedName(m.Name), used)
+		g.fields[name+"."+field] = m
+		typ := g.goType(m.Type)
+		if !m.Required && m.Init == nil && !isNilable(g.goType(g.resolve(m.Type))) {
+			typ = "*" + typ
+		}
+		g.printf("\t%s %s `json:\"%s,omitempty\"`\n", field, typ, m.Name)
+	}
+	g.printf("}\n")
+}
+
+func (g *generator) enum(d *ast.Enum) {
+	name := exportedName(d.Name)
+	g.types[name] = d
+	g.printf("\ntype %s string\n", name)
+	if len(d.Values) == 0 {
+		return
+	}
+	g.printf("\nconst (\n")
+	for _, v := range d.Values {
+		raw := v.Raw()
+		cname := uniqueName(name+constName(strings.Trim(raw, `"`)), g.names)
+		g.consts[cname] = enumValue{enum: d, value: v}
+		g.printf("\t%s %s = %s\n", cname, name, raw)
+	}
+	g.printf(")\n")
+}
+
+func (g *generator) typedef(d *ast.Typedef) {
+	name := exportedName(d.Name)
+	g.types[name] = d
+	g.printf("\ntype %s = %s\n", name, g.goType(d.Type))
+}
+
+// sourceMap maps lines of the formatted code to IDL nodes. It is built from the final
+// output, since gofmt may change the number of lines of the generated code.
+func (g *generator) sourceMap(data []byte) (SourceMap, error) {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", data, 0)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		smap  SourceMap
+		enums = make(map[*ast.Enum]int) // index of the enum mapping
+	)
+	add := func(n ast.Node, from, to goast.Node) int {
+		smap = append(smap, Mapping{
+			StartLine: fset.Position(from.Pos()).Line,
+			EndLine:   fset.Position(to.End()).Line,
+			Node:      n,
+		})
+		return len(smap) - 1
+	}
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*goast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			switch spec := spec.(type) {
+			case *goast.TypeSpec:
+				n := g.types[spec.Name.Name]
+				if n == nil {
+					continue
+				}
+				i := add(n, gd, gd)
+				if e, ok := n.(*ast.Enum); ok {
+					enums[e] = i
+				}
+				st, ok := spec.Type.(*goast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					for _, fname := range field.Names {
+						if m := g.fields[spec.Name.Name+"."+fname.Name]; m != nil {
+							add(m, field, field)
+						}
+					}
+				}
+			case *goast.ValueSpec:
+				for _, cname := range spec.Names {
+					v, ok := g.consts[cname.Name]
+					if !ok {
+						continue
+					}
+					add(v.value, spec, spec)
+					// the enum spans both the type and the constants
+					if i, ok := enums[v.enum]; ok {
+						smap[i].EndLine = fset.Position(gd.End()).Line
+					}
+				}
+			}
+		}
+	}
+	return smap, nil
+}
+
+// basicTypes maps IDL types to Go types.
+var basicTypes = map[string]string{
+	"boolean":             "bool",
+	"byte":                "int8",
+	"octet":               "uint8",
+	"short":               "int16",
+	"unsigned short":      "uint16",
+	"long":                "int32",
+	"unsigned long":       "uint32",
+	"long long":           "int64",
+	"unsigned long long":  "uint64",
+	"float":               "float32",
+	"unrestricted float":  "float32",
+	"double":              "float64",
+	"unrestricted double": "float64",
+	"DOMString":           "string",
+	"USVString":           "string",
+	"ByteString":          "string",
+	"CSSOMString":         "string",
+	"any":                 "interface{}",
+	"object":              "interface{}",
+	"undefined":           "struct{}",
+	"void":                "struct{}",
+}
+
+// goType returns the Go type for the IDL type.
+func (g *generator) goType(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.TypeName:
+		if s, ok := basicTypes[t.Name]; ok {
+			return s
+		}
+		if ast.IsBuiltin(t.Name) {
+			break
+		}
+		switch g.decls[t.Name].(type) {
+		case *ast.Dictionary, *ast.Enum, *ast.Typedef:
+			return exportedName(t.Name)
+		case *ast.Interface, *ast.Callback:
+			// objects and functions are opaque
+		default:
+			g.errorf("type %s is not declared in the file", t.Name)
+		}
+	case *ast.SequenceType:
+		return "[]" + g.goType(t.Elem)
+	case *ast.RecordType:
+		return "map[string]" + g.goType(t.Elem)
+	case *ast.ParametrizedType:
+		if t.Name == "FrozenArray" && len(t.Elems) == 1 {
+			return "[]" + g.goType(t.Elems[0])
+		}
+	case *ast.NullableType:
+		s := g.goType(t.Type)
+		if isNilable(s) {
+			return s
+		}
+		return "*" + s
+	}
+	return "interface{}"
+}
+
+// isNilable checks if the Go type already has a nil value.
+func isNilable(typ string) bool {
+	return strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") ||
+		strings.HasPrefix(typ, "*") || typ == "interface{}"
+}
+
+// exportedName converts an IDL identifier to an exported Go identifier.
+func exportedName(name string) string {
+	name = strings.TrimPrefix(name, "_")
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// uniqueName returns the name with the smallest numeric suffix that is not used yet,
+// or the name itself if it's not used, and marks the result as used.
+func uniqueName(name string, used map[string]bool) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = name + strconv.Itoa(i)
+	}
+	used[out] = true
+	return out
+}
+
+// constName converts an enum value to a suffix of the Go constant name.
+func constName(value string) string {
+	if value == "" {
+		return "Empty"
+	}
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = exportedName(w)
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,194 @@
+package gogen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+const testIDL = `enum Mode { "open", "closed-now", "" };
+typedef sequence<DOMString> Names;
+dictionary Options {
+	required long size;
+	Mode mode = "open";
+	boolean? flag;
+	Names names;
+};`
+
+func TestGenerateGo(t *testing.T) {
+	f := parser.MustParse(testIDL)
+	data, err := GenerateGo(f, "idl")
+	require.NoError(t, err)
+	// ignore alignment done by gofmt
+	out := strings.Join(strings.Fields(string(data)), " ")
+	require.Contains(t, out, "package idl")
+	require.Contains(t, out, `ModeClosedNow Mode = "closed-now"`)
+	require.Contains(t, out, `ModeEmpty Mode = ""`)
+	require.Contains(t, out, "type Names = []string")
+	require.Contains(t, out, "Size int32 `json:\"size,omitempty\"`")
+	require.Contains(t, out, "Flag *bool `json:\"flag,omitempty\"`")
+	require.Contains(t, out, "Names Names `json:\"names,omitempty\"`")
+}
+
+func TestGenerateGoWithMap(t *testing.T) {
+	f := parser.MustParse(testIDL)
+	data, smap, err := GenerateGoWithMap(f, "idl")
+	require.NoError(t, err)
+
+	lines := strings.Split(string(data), "\n")
+	line := 0
+	for i, l := range lines {
+		if strings.Contains(l, "Flag ") {
+			line = i + 1
+		}
+	}
+	require.NotZero(t, line)
+
+	n := smap.Lookup(line)
+	m, ok := n.(*ast.Member)
+	require.True(t, ok)
+	require.Equal(t, "flag", m.Name)
+	require.Equal(t, strings.Index(testIDL, "boolean? flag"), m.Start)
+
+	// the struct declaration line maps to the dictionary
+	for i, l := range lines {
+		if strings.HasPrefix(l, "type Options struct") {
+			line = i + 1
+		}
+	}
+	d, ok := smap.Lookup(line).(*ast.Dictionary)
+	require.True(t, ok)
+	require.Equal(t, "Options", d.Name)
+}
+
+func TestGenerateGoWithMapMultiple(t *testing.T) {
+	// empty enum is followed by other declarations, so all mappings after it must be exact
+	f := parser.MustParse(`enum Empty {};
+dictionary First {
+	long a;
+	DOMString b;
+};
+enum Kind { "x", "y" };
+typedef long Size;
+dictionary Last {
+	Kind kind;
+};`)
+	data, smap, err := GenerateGoWithMap(f, "idl")
+	require.NoError(t, err)
+	lines := strings.Split(string(data), "\n")
+	lineOf := func(prefix string) int {
+		for i, l := range lines {
+			if strings.HasPrefix(strings.TrimSpace(l), prefix) {
+				return i + 1
+			}
+		}
+		t.Fatalf("no line with %q in:\n%s", prefix, data)
+		return 0
+	}
+	name := func(n ast.Node) string {
+		switch n := n.(type) {
+		case *ast.Dictionary:
+			return n.Name
+		case *ast.Enum:
+			return n.Name
+		case *ast.Typedef:
+			return n.Name
+		case *ast.Member:
+			return n.Name
+		case *ast.EnumValue:
+			return n.Raw()
+		}
+		return ""
+	}
+	for prefix, exp := range map[string]string{
+		"type Empty string": "Empty",
+		"type First struct": "First",
+		"A *int32":          "a",
+		"B *string":         "b",
+		"type Kind string":  "Kind",
+		"const (":           "Kind",
+		"KindY":             `"y"`,
+		"type Size = int32": "Size",
+		"type Last struct":  "Last",
+		"Kind *Kind":        "kind",
+	} {
+		line := lineOf(prefix)
+		n := smap.Lookup(line)
+		require.NotNil(t, n, prefix)
+		require.Equal(t, exp, name(n), prefix)
+	}
+	// closing brace of the last dictionary
+	require.Equal(t, "Last", name(smap.Lookup(len(lines)-1)))
+}
+
+func TestGenerateGoNameClash(t *testing.T) {
+	f := parser.MustParse(`dictionary D {
+	long type;
+	long Type;
+	long type2;
+};
+enum Mode { "a-b", "a_b", "a b" };
+dictionary ModeAB2 {};`)
+	data, smap, err := GenerateGoWithMap(f, "idl")
+	require.NoError(t, err)
+	out := strings.Join(strings.Fields(string(data)), " ")
+	require.Contains(t, out, "Type *int32 `json:\"type,omitempty\"`")
+	require.Contains(t, out, "Type2 *int32 `json:\"Type,omitempty\"`")
+	require.Contains(t, out, "Type22 *int32 `json:\"type2,omitempty\"`")
+	// the name is taken by the dictionary
+	require.Contains(t, out, `ModeAB Mode = "a-b" ModeAB3 Mode = "a_b" ModeAB4 Mode = "a b"`)
+
+	lines := strings.Split(string(data), "\n")
+	for i, l := range lines {
+		if strings.Contains(l, "ModeAB3") {
+			v, ok := smap.Lookup(i + 1).(*ast.EnumValue)
+			require.True(t, ok)
+			require.Equal(t, `"a_b"`, v.Raw())
+		}
+	}
+}
+
+func TestGenerateGoTypeClash(t *testing.T) {
+	f := parser.MustParse(`dictionary foo {};
+dictionary Foo {};`)
+	_, err := GenerateGo(f, "idl")
+	require.EqualError(t, err, "declaration Foo clashes with another declaration as Go type Foo")
+
+	f = parser.MustParse(`dictionary D {};
+partial dictionary D { long x; };`)
+	_, err = GenerateGo(f, "idl")
+	require.EqualError(t, err, "partial dictionary D must be merged first")
+
+	data, err := GenerateGo(ast.MergePartials(f, ast.MergeOptions{}), "idl")
+	require.NoError(t, err)
+	require.Contains(t, string(data), "X *int32")
+}
+
+func TestGenerateGoReferences(t *testing.T) {
+	f := parser.MustParse(`interface Node {};
+callback Listener = undefined (Node n);
+dictionary D {
+	Node n;
+	required Listener cb;
+	sequence<Node?> list;
+	any value;
+};`)
+	data, err := GenerateGo(f, "idl")
+	require.NoError(t, err)
+	out := strings.Join(strings.Fields(string(data)), " ")
+	require.Contains(t, out, "N interface{} `json:\"n,omitempty\"`")
+	require.Contains(t, out, "Cb interface{} `json:\"cb,omitempty\"`")
+	require.Contains(t, out, "List []interface{} `json:\"list,omitempty\"`")
+	require.Contains(t, out, "Value interface{} `json:\"value,omitempty\"`")
+
+	f = parser.MustParse(`dictionary D { Node n; };`)
+	_, err = GenerateGo(f, "idl")
+	require.EqualError(t, err, "type Node is not declared in the file")
+
+	f = parser.MustParse(`dictionary D : Base {};`)
+	_, err = GenerateGo(f, "idl")
+	require.EqualError(t, err, "dictionary D inherits from Base, which is not a dictionary declared in the file")
+}
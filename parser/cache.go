@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/dennwc/webidl/ast"
+)
+
+// DefaultCacheSize is the default number of parse trees kept by ParseCached.
+const DefaultCacheSize = 64
+
+var cache = newParseCache(DefaultCacheSize)
+
+// SetCacheSize sets the maximal number of parse trees kept by ParseCached.
+// Zero disables the cache.
+func SetCacheSize(n int) {
+	cache.resize(n)
+}
+
+// ParseCached is like Parse, but keeps recently parsed trees in an LRU cache keyed by
+// the hash of the input. Each call returns a deep copy of the cached tree, so it's safe
+// to modify the result.
+func ParseCached(input string) *ast.File {
+	key := sha256.Sum256([]byte(input))
+	f := cache.get(key)
+	if f == nil {
+		f = Parse(input)
+		cache.put(key, f)
+	}
+	return ast.Clone(f).(*ast.File)
+}
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key  cacheKey
+	file *ast.File
+}
+
+// parseCache is an LRU cache of parse trees.
+type parseCache struct {
+	mu    sync.Mutex
+	size  int
+	lru   *list.List // of *cacheEntry, most recently used first
+	items map[cacheKey]*list.Element
+}
+
+func newParseCache(size int) *parseCache {
+	return &parseCache{
+		size:  size,
+		lru:   list.New(),
+		items: make(map[cacheKey]*list.Element),
+	}
+}
+
+func (c *parseCache) get(key cacheKey) *ast.File {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry).file
+}
+
+func (c *parseCache) put(key cacheKey, f *ast.File) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*cacheEntry).file = f
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.lru.PushFront(&cacheEntry{key: key, file: f})
+	c.evict()
+}
+
+func (c *parseCache) resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size = size
+	c.evict()
+}
+
+// evict removes the least recently used entries until the cache fits its size.
+func (c *parseCache) evict() {
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.items, e.Value.(*cacheEntry).key)
+	}
+}
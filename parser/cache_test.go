@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCached(t *testing.T) {
+	const src = `interface Foo { attribute long x; };`
+	f1 := ParseCached(src)
+	f2 := ParseCached(src)
+	require.Equal(t, f1, f2)
+	require.True(t, f1 != f2)
+
+	f1.Declarations[0].(*ast.Interface).Name = "Bar"
+	require.Equal(t, "Foo", f2.Declarations[0].(*ast.Interface).Name)
+	require.Equal(t, "Foo", ParseCached(src).Declarations[0].(*ast.Interface).Name)
+}
+
+func TestParseCacheEviction(t *testing.T) {
+	c := newParseCache(2)
+	keys := []cacheKey{{1}, {2}, {3}}
+	for _, k := range keys[:2] {
+		c.put(k, &ast.File{})
+	}
+	require.NotNil(t, c.get(keys[0]))
+	c.put(keys[2], &ast.File{})
+	require.NotNil(t, c.get(keys[0]))
+	require.Nil(t, c.get(keys[1]))
+	require.NotNil(t, c.get(keys[2]))
+
+	c.resize(0)
+	require.Nil(t, c.get(keys[0]))
+	require.Nil(t, c.get(keys[2]))
+}
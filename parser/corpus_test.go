@@ -0,0 +1,118 @@
+//go:build corpus
+// +build corpus
+
+package parser
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+// corpusDir contains real-world WebIDL files, one file per specification.
+// The corpus is not a part of the repository. Missing files are downloaded from the ed/idl
+// directory of https://github.com/w3c/webref at the commit set by the -corpus.ref flag:
+//
+//	go test -tags corpus -run TestCorpus ./parser -corpus.ref=<commit>
+//
+// The commit is recorded in the corpusRefFile, so later runs use the same files without
+// the flag. Delete the directory to download a different commit.
+const corpusDir = "./tests/corpus"
+
+// corpusRefFile is the name of the file in corpusDir with the webref commit of the corpus.
+const corpusRefFile = "REF"
+
+var corpusRef = flag.String("corpus.ref", "", "commit of w3c/webref to download the corpus from")
+
+// corpusSpecs lists files of the corpus, by the name of the specification.
+var corpusSpecs = map[string]string{
+	"html":   "html.idl",
+	"dom":    "dom.idl",
+	"fetch":  "fetch.idl",
+	"webgl1": "webgl1.idl",
+	"webgl2": "webgl2.idl",
+}
+
+// corpusKnownFailures lists specifications from the corpus that are known to fail,
+// with the reason. They are still parsed, but errors are only logged.
+//
+// TODO: fill the list from a run against a pinned commit; the corpus was not downloaded yet.
+var corpusKnownFailures = map[string]string{}
+
+// fetchCorpus downloads files of the corpus that are not present in corpusDir,
+// and returns the webref commit of the corpus.
+func fetchCorpus() (string, error) {
+	refPath := filepath.Join(corpusDir, corpusRefFile)
+	ref := *corpusRef
+	if data, err := ioutil.ReadFile(refPath); err == nil {
+		cur := strings.TrimSpace(string(data))
+		if ref != "" && ref != cur {
+			return "", fmt.Errorf("the corpus is downloaded from %s, delete %s to use %s", cur, corpusDir, ref)
+		}
+		ref = cur
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	if ref == "" {
+		return "", fmt.Errorf("set -corpus.ref to a w3c/webref commit")
+	}
+	if err := os.MkdirAll(corpusDir, 0755); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(refPath, []byte(ref+"\n"), 0644); err != nil {
+		return "", err
+	}
+	for _, file := range corpusSpecs {
+		path := filepath.Join(corpusDir, file)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		url := "https://raw.githubusercontent.com/w3c/webref/" + ref + "/ed/idl/" + file
+		resp, err := http.Get(url)
+		if err != nil {
+			return "", err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", err
+		} else if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s: %s", url, resp.Status)
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return "", err
+		}
+	}
+	return ref, nil
+}
+
+func TestCorpus(t *testing.T) {
+	ref, err := fetchCorpus()
+	if err != nil {
+		t.Skip("cannot download the corpus: " + err.Error())
+	}
+	t.Logf("corpus commit: %s", ref)
+	for name, file := range corpusSpecs {
+		name, path := name, filepath.Join(corpusDir, file)
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			errs := ast.AllErrors(Parse(string(data)))
+			if reason, ok := corpusKnownFailures[name]; ok {
+				t.Logf("known failure: %s (%d errors)", reason, len(errs))
+				return
+			}
+			for _, e := range errs {
+				t.Errorf("%s:%v", path, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"github.com/dennwc/webidl/ast"
+)
+
+// DeclHandler parses a vendor-specific top-level declaration.
+//
+// It is called when the current token is one of the dialect keywords. If the handler
+// doesn't recognize the declaration, it must return false without consuming any tokens.
+type DeclHandler func(p DeclParser) (ast.Decl, bool)
+
+// Dialect describes vendor extensions to the WebIDL grammar, such as the ones used
+// by Chromium or WebKit IDL files.
+type Dialect struct {
+	// Keywords that start a vendor-specific top-level declaration.
+	Keywords []string
+	// Handlers are tried in order for each declaration starting with one of the Keywords.
+	Handlers []DeclHandler
+}
+
+// apply registers the dialect extensions in the parser configuration.
+func (d Dialect) apply(config *parserConfig) {
+	if len(d.Keywords) != 0 && config.extraKeywords == nil {
+		config.extraKeywords = make(map[string]struct{}, len(d.Keywords))
+	}
+	for _, k := range d.Keywords {
+		config.extraKeywords[k] = struct{}{}
+	}
+	config.declHandlers = append(config.declHandlers, d.Handlers...)
+}
+
+// DeclParser provides access to tokens of the source being parsed for dialect handlers.
+// It is only valid during the call of the handler.
+type DeclParser struct {
+	p   *sourceParser
+	ann []*ast.Annotation
+}
+
+// Annotations returns extended attributes that precede the declaration.
+func (p DeclParser) Annotations() []*ast.Annotation {
+	return p.ann
+}
+
+// IsKeyword checks if the current token is a given keyword.
+func (p DeclParser) IsKeyword(keyword string) bool {
+	return p.p.isIdentifier(keyword)
+}
+
+// TryConsumeKeyword consumes a keyword, if it's the current token.
+func (p DeclParser) TryConsumeKeyword(keyword string) bool {
+	return p.p.tryConsumeKeyword(keyword)
+}
+
+// ConsumeKeyword consumes an expected keyword or records an error.
+func (p DeclParser) ConsumeKeyword(keyword string) bool {
+	return p.p.consumeKeyword(keyword)
+}
+
+// ConsumeIdentifier consumes an expected identifier or records an error.
+func (p DeclParser) ConsumeIdentifier() string {
+	return p.p.consumeIdentifier()
+}
+
+// ConsumeType consumes a type.
+func (p DeclParser) ConsumeType() ast.Type {
+	return p.p.consumeType()
+}
+
+// ConsumeSemicolon consumes an expected semicolon or records an error.
+func (p DeclParser) ConsumeSemicolon() bool {
+	_, ok := p.p.consume(tokenTypeSemicolon)
+	return ok
+}
+
+// Errorf records an error at the current position.
+func (p DeclParser) Errorf(format string, args ...interface{}) {
+	p.p.emitError(format, args...)
+}
+
+// isExtraKeyword checks if the current token is one of the dialect keywords.
+func (p *sourceParser) isExtraKeyword() bool {
+	if !p.isToken(tokenTypeIdentifier) {
+		return false
+	}
+	_, ok := p.config.extraKeywords[p.currentToken.value]
+	return ok
+}
+
+// tryConsumeDialectDecl runs dialect handlers until one of them recognizes the declaration.
+func (p *sourceParser) tryConsumeDialectDecl(ann []*ast.Annotation, base *ast.Base, finish func()) (ast.Decl, bool) {
+	for _, h := range p.config.declHandlers {
+		n, ok := h(DeclParser{p: p, ann: ann})
+		if !ok || n == nil {
+			continue
+		}
+		finish()
+		b := n.NodeBase()
+		errs := b.Errors
+		*b = *base
+		b.Errors = append(b.Errors, errs...)
+		return n, true
+	}
+	return nil, false
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDialect(t *testing.T) {
+	d := Dialect{
+		Keywords: []string{"wrapper"},
+		Handlers: []DeclHandler{
+			func(p DeclParser) (ast.Decl, bool) {
+				if !p.TryConsumeKeyword("wrapper") {
+					return nil, false
+				}
+				n := &ast.CustomDecl{Keyword: "wrapper", Annotations: p.Annotations()}
+				n.Name = p.ConsumeIdentifier()
+				p.ConsumeSemicolon()
+				return n, true
+			},
+		},
+	}
+	const src = `[SpecialWrapFor=Window] wrapper Foo;
+interface Bar {};`
+	f := ParseDialect(src, d)
+	require.Len(t, f.Declarations, 2)
+	n, ok := f.Declarations[0].(*ast.CustomDecl)
+	require.True(t, ok)
+	require.Equal(t, "wrapper", n.Keyword)
+	require.Equal(t, "Foo", n.Name)
+	require.Len(t, n.Annotations, 1)
+	require.Equal(t, "SpecialWrapFor", n.Annotations[0].Name)
+	require.Empty(t, n.Errors)
+	require.Equal(t, 0, n.Start)
+	require.Equal(t, 35, n.End)
+
+	_, ok = f.Declarations[1].(*ast.Interface)
+	require.True(t, ok)
+
+	// without the dialect the keyword is rejected
+	f = Parse(src)
+	require.NotEmpty(t, f.Declarations[0].NodeBase().Errors)
+}
@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/dennwc/webidl/ast"
+)
+
+// isDocComment checks if the comment is a documentation comment: /** ... */.
+func isDocComment(comment string) bool {
+	return strings.HasPrefix(comment, "/**") && comment != "/**/" &&
+		strings.HasSuffix(comment, "*/")
+}
+
+// parseDocComment extracts text lines from a documentation comment.
+func parseDocComment(comment string) *ast.DocComment {
+	text := strings.TrimSuffix(strings.TrimPrefix(comment, "/**"), "*/")
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "*") {
+			line = strings.TrimPrefix(line, "*")
+			line = strings.TrimPrefix(line, " ")
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	// drop leading and trailing empty lines
+	for len(lines) != 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return &ast.DocComment{Lines: lines}
+}
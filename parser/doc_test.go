@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocComment(t *testing.T) {
+	d := parseDecl(t, `// not a doc
+/**
+ * Foo does things.
+ *
+ *   Indented details.
+ */
+interface Foo {
+	/** The x value. */
+	attribute long x;
+	/* not a doc */
+	attribute long y;
+};`)
+	iface := d.(*ast.Interface)
+	require.NotNil(t, iface.Doc)
+	require.Equal(t, []string{"Foo does things.", "", "  Indented details."}, iface.Doc.Lines)
+	require.Equal(t, "Foo does things.\n\n  Indented details.", iface.Doc.Text())
+	require.Len(t, iface.Comments, 2)
+
+	x := iface.Member("x")
+	require.Equal(t, "The x value.", x.Doc.Text())
+
+	y := iface.Member("y")
+	require.Nil(t, y.Doc)
+	require.Equal(t, "", y.Doc.Text())
+}
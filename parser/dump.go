@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 
 	"github.com/dennwc/webidl/ast"
@@ -20,3 +21,11 @@ func DumpString(n ast.Node) string {
 	}
 	return buf.String()
 }
+
+// DumpJSON writes the tree as indented JSON. The output mirrors the shape of the ast structs;
+// the type of interface fields (like Type or Init) is not recorded.
+func DumpJSON(w io.Writer, n ast.Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(n)
+}
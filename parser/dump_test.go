@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpJSON(t *testing.T) {
+	f := Parse(`interface Foo { attribute long x; };`)
+	var buf bytes.Buffer
+	err := DumpJSON(&buf, f)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `"Name": "Foo"`)
+
+	var out struct {
+		Declarations []struct {
+			Name    string
+			Members []struct {
+				Name string
+				Type struct{ Name string }
+			}
+		}
+	}
+	err = json.Unmarshal(buf.Bytes(), &out)
+	require.NoError(t, err)
+	require.Equal(t, "Foo", out.Declarations[0].Name)
+	require.Equal(t, "x", out.Declarations[0].Members[0].Name)
+	require.Equal(t, "long", out.Declarations[0].Members[0].Type.Name)
+}
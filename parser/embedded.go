@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dennwc/webidl/ast"
+)
+
+var (
+	// reFenceIDL matches ```webidl fenced code blocks in Markdown.
+	reFenceIDL = regexp.MustCompile("(?ms)^[ \\t]*```webidl[ \\t]*\\n(.*?)^[ \\t]*```")
+	// rePreIDL matches <pre class=idl> blocks in HTML, as used by bikeshed and respec.
+	// The idl class must be a whole token of the quoted or unquoted attribute value.
+	rePreIDL = regexp.MustCompile(`(?is)<pre\s+(?:[^>]*\s)?class=(?:"(?:[^"]*\s)?idl(?:\s[^"]*)?"|'(?:[^']*\s)?idl(?:\s[^']*)?'|idl)(?:\s[^>]*)?>(.*?)</pre>`)
+)
+
+// ParseEmbedded extracts WebIDL blocks from a Markdown or HTML document and parses each of them.
+//
+// IDL blocks are either ```webidl fenced code blocks or <pre class=idl> tags. Node positions
+// and lines in returned files are relative to the whole document. HTML entities in the blocks
+// are not decoded.
+//
+// It returns an ErrorList if any errors were found during parsing. Files are always returned,
+// even if they are incomplete.
+func ParseEmbedded(doc string) ([]*ast.File, error) {
+	type block struct{ start, end int }
+	var blocks []block
+	for _, re := range []*regexp.Regexp{reFenceIDL, rePreIDL} {
+		for _, m := range re.FindAllStringSubmatchIndex(doc, -1) {
+			blocks = append(blocks, block{start: m[2], end: m[3]})
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].start < blocks[j].start
+	})
+	var (
+		p     = NewParser(Options{})
+		files []*ast.File
+		errs  ErrorList
+	)
+	for _, b := range blocks {
+		line := strings.Count(doc[:b.start], "\n")
+		f := p.parseAt(doc[b.start:b.end], bytePosition(b.start), lineNumber(line))
+		errs = append(errs, ast.AllErrors(f)...)
+		files = append(files, f)
+	}
+	if len(errs) != 0 {
+		return files, errs
+	}
+	return files, nil
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmbedded(t *testing.T) {
+	const doc = "# Spec\n" +
+		"\n" +
+		"Some text.\n" +
+		"\n" +
+		"```webidl\n" +
+		"interface Foo {\n" +
+		"  attribute long x;\n" +
+		"};\n" +
+		"```\n" +
+		"\n" +
+		"```js\n" +
+		"interface Ignored {};\n" +
+		"```\n" +
+		"\n" +
+		"```webidl\n" +
+		"dictionary Bar {\n" +
+		"  long y = 1;\n" +
+		"};\n" +
+		"```\n"
+	files, err := ParseEmbedded(doc)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	foo := files[0].Declarations[0].(*ast.Interface)
+	require.Equal(t, "Foo", foo.Name)
+	require.Equal(t, strings.Index(doc, "interface Foo"), foo.Start)
+	require.Equal(t, 6, foo.Line)
+
+	bar := files[1].Declarations[0].(*ast.Dictionary)
+	require.Equal(t, "Bar", bar.Name)
+	require.Equal(t, strings.Index(doc, "dictionary Bar"), bar.Start)
+	require.Equal(t, 16, bar.Line)
+	require.Equal(t, "y", bar.Members[0].Name)
+	require.Equal(t, strings.Index(doc, "long y"), bar.Members[0].Start)
+}
+
+func TestParseEmbeddedHTML(t *testing.T) {
+	const doc = `<p>Intro</p>
+<pre class="idl">
+interface Foo {};
+</pre>
+<pre class=idl>interface Bar {</pre>`
+	files, err := ParseEmbedded(doc)
+	require.Error(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "Foo", files[0].Declarations[0].(*ast.Interface).Name)
+	require.Equal(t, strings.Index(doc, "interface Foo"), files[0].Declarations[0].NodeBase().Start)
+	require.Equal(t, 5, err.(ErrorList)[0].Line)
+}
+
+func TestParseEmbeddedHTMLClass(t *testing.T) {
+	const doc = `<pre class="idl extract">interface A {};</pre>
+<pre id=x class='highlight idl'>interface B {};</pre>
+<pre class="idlnotes">Notes about { the IDL</pre>
+<pre class=idlnotes>Notes</pre>
+<pre data-class="idl">Not IDL {</pre>
+<pre class="example" title="idl">Not IDL {</pre>`
+	files, err := ParseEmbedded(doc)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "A", files[0].Declarations[0].(*ast.Interface).Name)
+	require.Equal(t, "B", files[1].Declarations[0].(*ast.Interface).Name)
+}
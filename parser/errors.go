@@ -0,0 +1,20 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/dennwc/webidl/ast"
+)
+
+// ErrorList is a list of errors found in the source, sorted by position.
+type ErrorList []*ast.ErrorNode
+
+func (list ErrorList) Error() string {
+	switch len(list) {
+	case 0:
+		return "no errors"
+	case 1:
+		return list[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", list[0].Error(), len(list)-1)
+}
@@ -19,6 +19,9 @@ import (
 
 const EOFRUNE = -1
 
+// byteOrderMark is a UTF-8 encoded BOM that may prefix the input.
+const byteOrderMark = "\uFEFF"
+
 type isWhitespaceTokenChecker func(kind tokenType) bool
 type lexSourceImpl func(l *lexer) stateFn
 
@@ -27,18 +30,24 @@ func buildlex(input string, impl lexSourceImpl, whitespace isWhitespaceTokenChec
 	l := &lexer{
 		input:             input,
 		tokens:            make(chan lexeme),
+		done:              make(chan struct{}),
 		isWhitespaceToken: whitespace,
 		lexSource:         impl,
 		line:              1,
 		startLine:         1,
 	}
+	if strings.HasPrefix(input, byteOrderMark) {
+		// skip it, but keep positions relative to the input
+		l.pos = bytePosition(len(byteOrderMark))
+		l.start = l.pos
+	}
 	go l.run()
 	return l
 }
 
 // run runs the state machine for the lexer.
 func (l *lexer) run() {
-	for l.state = lexSource; l.state != nil; {
+	for l.state = lexSource; l.state != nil && !l.stopped; {
 		l.state = l.state(l)
 	}
 	close(l.tokens)
@@ -65,18 +74,20 @@ type stateFn func(*lexer) stateFn
 
 // lexer holds the state of the scanner.
 type lexer struct {
-	input                  string       // the string being scanned
-	state                  stateFn      // the next lexing function to enter
-	pos                    bytePosition // current position in the input
-	start                  bytePosition // start position of this token
-	width                  bytePosition // width of last rune read from input
-	lastPos                bytePosition // position of most recent token returned by nextToken
-	tokens                 chan lexeme  // channel of scanned lexemes
-	currentToken           lexeme       // The current token if any
-	lastNonWhitespaceToken lexeme       // The last token returned that is non-whitespace
-	line                   lineNumber   // current line number
-	startLine              lineNumber   // line number for next token
-	nextWasNL              bool         // last next() was a new line rune
+	input                  string        // the string being scanned
+	state                  stateFn       // the next lexing function to enter
+	pos                    bytePosition  // current position in the input
+	start                  bytePosition  // start position of this token
+	width                  bytePosition  // width of last rune read from input
+	lastPos                bytePosition  // position of most recent token returned by nextToken
+	tokens                 chan lexeme   // channel of scanned lexemes
+	done                   chan struct{} // closed when the client stops reading tokens
+	stopped                bool          // the client stopped reading tokens
+	currentToken           lexeme        // The current token if any
+	lastNonWhitespaceToken lexeme        // The last token returned that is non-whitespace
+	line                   lineNumber    // current line number
+	startLine              lineNumber    // line number for next token
+	nextWasNL              bool          // last next() was a new line rune
 
 	isWhitespaceToken isWhitespaceTokenChecker
 	lexSource         lexSourceImpl
@@ -89,6 +100,27 @@ func (l *lexer) nextToken() lexeme {
 	return token
 }
 
+// stop terminates the lexer goroutine when the client stops before the end of input.
+// The lexer stops scanning at the next token it tries to send. It must be called at most once.
+func (l *lexer) stop() {
+	close(l.done)
+}
+
+// send passes a token to the client, unless it stopped reading tokens.
+func (l *lexer) send(t lexeme) {
+	select {
+	case <-l.done:
+		l.stopped = true
+		return
+	default:
+	}
+	select {
+	case l.tokens <- t:
+	case <-l.done:
+		l.stopped = true
+	}
+}
+
 // next returns the next rune in the input.
 func (l *lexer) next() rune {
 	if int(l.pos) >= len(l.input) {
@@ -157,7 +189,7 @@ func (l *lexer) emitWith(t tokenType, val string) {
 		l.lastNonWhitespaceToken = currentToken
 	}
 
-	l.tokens <- currentToken
+	l.send(currentToken)
 	l.currentToken = currentToken
 	l.start = l.pos
 	l.startLine = l.line
@@ -166,7 +198,7 @@ func (l *lexer) emitWith(t tokenType, val string) {
 // errorf returns an error token and terminates the scan by passing
 // back a nil pointer that will be the next state, terminating l.nexttoken.
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
-	l.tokens <- lexeme{tokenTypeError, l.start, l.startLine, fmt.Sprintf(format, args...)}
+	l.send(lexeme{tokenTypeError, l.start, l.startLine, fmt.Sprintf(format, args...)})
 	return nil
 }
 
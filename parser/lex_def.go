@@ -42,6 +42,7 @@ const (
 	tokenTypeQuestionMark // ?
 	tokenTypeColon        // :
 	tokenTypeVariadic     // ...
+	tokenTypePipe         // |
 )
 
 func isWhitespaceToken(kind tokenType) bool {
@@ -52,6 +53,10 @@ func isWhitespaceToken(kind tokenType) bool {
 func performLexSource(l *lexer) stateFn {
 Loop:
 	for {
+		if l.stopped {
+			// the client stopped reading tokens, don't scan the rest of the input
+			return nil
+		}
 		switch r := l.next(); {
 		case r == EOFRUNE:
 			break Loop
@@ -86,6 +91,9 @@ Loop:
 		case r == ',':
 			l.emit(tokenTypeComma)
 
+		case r == '|':
+			l.emit(tokenTypePipe)
+
 		case r == '.':
 			if l.acceptString("..") {
 				l.emit(tokenTypeVariadic)
@@ -34,6 +34,11 @@ var lexerTests = []lexerTest{
 	{"newline rn", "\r\n", []lexeme{{tokenTypeWhitespace, 0, 0, "\r"}, {tokenTypeWhitespace, 0, 0, "\n"}, tEOF}},
 
 	{"comment", "// a comment", []lexeme{{tokenTypeComment, 0, 0, "// a comment"}, tEOF}},
+	{"comment crlf", "// a comment\r\nfoo", []lexeme{
+		{tokenTypeComment, 0, 0, "// a comment"}, {tokenTypeWhitespace, 0, 0, "\r"}, {tokenTypeWhitespace, 0, 0, "\n"},
+		{tokenTypeIdentifier, 0, 0, "foo"}, tEOF,
+	}},
+	{"bom", "\uFEFFfoo", []lexeme{{tokenTypeIdentifier, 0, 0, "foo"}, tEOF}},
 	{"multiline comment", "/* a comment */foo", []lexeme{
 		{tokenTypeComment, 0, 0, "/* a comment */"}, {tokenTypeIdentifier, 0, 0, "foo"}, tEOF,
 	}},
@@ -53,6 +58,7 @@ var lexerTests = []lexerTest{
 	{"semicolon", ";", []lexeme{{tokenTypeSemicolon, 0, 0, ";"}, tEOF}},
 	{"comma", ",", []lexeme{{tokenTypeComma, 0, 0, ","}, tEOF}},
 	{"variadic", "...", []lexeme{{tokenTypeVariadic, 0, 0, "..."}, tEOF}},
+	{"pipe", "|", []lexeme{{tokenTypePipe, 0, 0, "|"}, tEOF}},
 
 	{"keyword", "interface", []lexeme{{tokenTypeIdentifier, 0, 0, "interface"}, tEOF}},
 	{"identifier", "interace", []lexeme{{tokenTypeIdentifier, 0, 0, "interace"}, tEOF}},
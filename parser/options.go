@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"github.com/dennwc/webidl/ast"
+)
+
+// DeprecationMode defines how the parser reports deprecated syntax, like void or implements.
+type DeprecationMode int
+
+const (
+	DeprecationsIgnore DeprecationMode = iota // accept deprecated syntax silently
+	DeprecationsWarn                          // report deprecated syntax as warnings
+	DeprecationsError                         // report deprecated syntax as errors
+)
+
+// Options for the parser. Zero value parses standard WebIDL.
+type Options struct {
+	// Deprecations controls how deprecated syntax is reported.
+	Deprecations DeprecationMode
+	// Dialect adds vendor extensions to the grammar.
+	Dialect Dialect
+	// KeepSource retains the source text in the File node.
+	KeepSource bool
+	// IgnoreKeywordCase matches keywords of declarations and members, like "interface"
+	// or "readonly", case-insensitively. WebIDL is case-sensitive, but this helps to ingest
+	// hand-edited files. Names and type keywords, like "sequence", are not affected.
+	IgnoreKeywordCase bool
+	// RecoverErrors continues parsing after structural errors, like a missing semicolon
+	// or an unexpected token, by skipping to the next member or declaration boundary.
+	// This way a single pass reports all problems in the file, which is useful for linters.
+	RecoverErrors bool
+}
+
+// config returns the parser configuration for these options.
+func (opt Options) config() parserConfig {
+	config := parserConfig{
+		ignoredTokenTypes: map[tokenType]struct{}{
+			tokenTypeWhitespace: {},
+			tokenTypeComment:    {},
+		},
+		deprecations:      opt.Deprecations,
+		keepSource:        opt.KeepSource,
+		ignoreKeywordCase: opt.IgnoreKeywordCase,
+		recoverErrors:     opt.RecoverErrors,
+	}
+	opt.Dialect.apply(&config)
+	return config
+}
+
+// Parser parses WebIDL sources with specific options.
+//
+// The options are prepared once, so a single Parser can be used to parse multiple sources.
+// Each source is parsed with a new internal state, so it's safe for concurrent use.
+// Parsers must be created with NewParser.
+type Parser struct {
+	config parserConfig
+}
+
+// NewParser creates a new parser with given options.
+func NewParser(opt Options) *Parser {
+	return &Parser{config: opt.config()}
+}
+
+// Parse parses the given WebIDL source into a parse tree.
+// It returns an ErrorList if any errors were found during parsing.
+// The tree is always returned, even if it's incomplete.
+func (p *Parser) Parse(input string) (*ast.File, error) {
+	f := p.parseAt(input, 0, 0)
+	if errs := ast.AllErrors(f); len(errs) != 0 {
+		return f, ErrorList(errs)
+	}
+	return f, nil
+}
+
+// parseAt parses the input that starts at a given offset and line offset of a larger document.
+// Errors are only attached to the tree, callers must collect them if needed.
+func (p *Parser) parseAt(input string, start bytePosition, line lineNumber) *ast.File {
+	sp := buildParser(lex(input), p.config, start)
+	sp.startLine = line
+	f := sp.consumeTopLevel()
+	if p.config.keepSource {
+		f.Source = input
+	}
+	return f
+}
+
+// parse parses the given WebIDL source with specific options, without collecting errors.
+func parse(input string, opt Options) *ast.File {
+	return NewParser(opt).parseAt(input, 0, 0)
+}
+
+// ParseWith parses the given WebIDL source into a parse tree, using specific options.
+// It returns an ErrorList if any errors were found during parsing.
+// The tree is always returned, even if it's incomplete.
+func ParseWith(input string, opt Options) (*ast.File, error) {
+	return NewParser(opt).Parse(input)
+}
+
+// Parse parses the given WebIDL source into a parse tree.
+func Parse(input string) *ast.File {
+	return parse(input, Options{})
+}
+
+// ParseErr parses the given WebIDL source into a parse tree.
+// It returns an ErrorList if any errors were found during parsing.
+// The tree is always returned, even if it's incomplete.
+func ParseErr(input string) (*ast.File, error) {
+	return ParseWith(input, Options{})
+}
+
+// MustParse is like ParseErr, but panics if the source cannot be parsed.
+// It simplifies initialization of global variables holding embedded IDL.
+func MustParse(input string) *ast.File {
+	f, err := ParseErr(input)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// ParseLenient parses the given WebIDL source into a parse tree, reporting deprecated
+// syntax (like void or implements) as warnings. Trailing commas in argument and extended
+// attribute lists are accepted with a warning as well.
+func ParseLenient(input string) *ast.File {
+	return parse(input, Options{Deprecations: DeprecationsWarn})
+}
+
+// ParseStrict parses the given WebIDL source into a parse tree, reporting deprecated
+// syntax (like void or implements) as errors.
+func ParseStrict(input string) *ast.File {
+	return parse(input, Options{Deprecations: DeprecationsError})
+}
+
+// ParseDialect parses the given WebIDL source, extended with a given dialect, into a parse tree.
+func ParseDialect(input string, d Dialect) *ast.File {
+	return parse(input, Options{Dialect: d})
+}
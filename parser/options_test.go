@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWith(t *testing.T) {
+	opt := Options{
+		Deprecations: DeprecationsWarn,
+		Dialect: Dialect{
+			Keywords: []string{"legacy"},
+			Handlers: []DeclHandler{
+				func(p DeclParser) (ast.Decl, bool) {
+					if !p.TryConsumeKeyword("legacy") {
+						return nil, false
+					}
+					n := &ast.CustomDecl{Keyword: "legacy"}
+					n.Name = p.ConsumeIdentifier()
+					p.ConsumeSemicolon()
+					return n, true
+				},
+			},
+		},
+	}
+	const src = `legacy Foo;
+interface Bar {
+	void f();
+};`
+	f, err := ParseWith(src, opt)
+	require.NoError(t, err)
+	require.Len(t, f.Declarations, 2)
+	require.IsType(t, &ast.CustomDecl{}, f.Declarations[0])
+	require.Len(t, f.Warnings(), 1)
+
+	opt.Deprecations = DeprecationsError
+	f, err = ParseWith(src, opt)
+	require.Error(t, err)
+	require.Len(t, f.Declarations, 2)
+	require.Len(t, err.(ErrorList), 1)
+}
+
+func TestParser(t *testing.T) {
+	p := NewParser(Options{})
+	for _, src := range []string{
+		`interface Foo { attribute long x; };`,
+		`dictionary Bar { long y = 1; };`,
+		`interface Foo { attribute long x; };`,
+	} {
+		f, err := p.Parse(src)
+		require.NoError(t, err)
+		require.Equal(t, DumpString(Parse(src)), DumpString(f))
+	}
+	_, err := p.Parse(`interface Foo {`)
+	require.Error(t, err)
+	f, err := p.Parse(`interface Foo {};`)
+	require.NoError(t, err)
+	require.Len(t, f.Declarations, 1)
+}
+
+func TestParserNested(t *testing.T) {
+	// the handler parses another source with the same parser in the middle of the file
+	var p *Parser
+	p = NewParser(Options{Dialect: Dialect{
+		Keywords: []string{"nested"},
+		Handlers: []DeclHandler{
+			func(dp DeclParser) (ast.Decl, bool) {
+				if !dp.TryConsumeKeyword("nested") {
+					return nil, false
+				}
+				n := &ast.CustomDecl{Keyword: "nested"}
+				n.Name = dp.ConsumeIdentifier()
+				dp.ConsumeSemicolon()
+				f, err := p.Parse(`interface Inner { attribute long y; };`)
+				require.NoError(t, err)
+				require.Len(t, f.Declarations, 1)
+				return n, true
+			},
+		},
+	}})
+	f, err := p.Parse(`interface A { attribute long x; };
+nested B;
+interface C {};`)
+	require.NoError(t, err)
+	require.Len(t, f.Declarations, 3)
+	require.Equal(t, "B", f.Declarations[1].(*ast.CustomDecl).Name)
+	require.Equal(t, "C", f.Declarations[2].(*ast.Interface).Name)
+}
+
+func benchmarkSource(b *testing.B) string {
+	data, err := ioutil.ReadFile("./tests/DOM.webidl")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return string(data)
+}
+
+func BenchmarkParse(b *testing.B) {
+	src := benchmarkSource(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Parse(src)
+	}
+}
+
+func BenchmarkParserReuse(b *testing.B) {
+	src := benchmarkSource(b)
+	p := NewParser(Options{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(src)
+	}
+}
+
+// typeHeavySource is a source where most of the tokens are parts of types.
+var typeHeavySource = "interface Types {\n" + strings.Repeat(`	attribute unsigned long long a;
+	attribute unrestricted double? b;
+	attribute long long c;
+	attribute sequence<unsigned short> d;
+	attribute (DOMString or unsigned long or Node)? e;
+	attribute record<DOMString, unrestricted float> f;
+	attribute Promise<sequence<long long>> g;
+`, 50) + "};\n"
+
+func BenchmarkConsumeType(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Parse(typeHeavySource)
+	}
+}
+
+func TestIgnoreKeywordCase(t *testing.T) {
+	const src = `Interface Foo {
+	READONLY Attribute long Value;
+	Getter long item(unsigned long index);
+};`
+	_, err := ParseErr(src)
+	require.Error(t, err)
+
+	f, err := ParseWith(src, Options{IgnoreKeywordCase: true})
+	require.NoError(t, err)
+	iface := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "Foo", iface.Name)
+	m := iface.Member("Value")
+	require.NotNil(t, m)
+	require.True(t, m.Readonly)
+	require.True(t, m.Attribute)
+	require.Equal(t, "getter", iface.Member("item").Specialization)
+}
+
+func TestIgnoreKeywordCaseTypes(t *testing.T) {
+	// types named like type keywords are not affected
+	f, err := ParseWith(`dictionary Record {};
+dictionary Sequence {};
+interface Foo {
+	Attribute Record x;
+	Readonly Attribute Sequence? y;
+	(Record or Any) f(Sequence s);
+};`, Options{IgnoreKeywordCase: true})
+	require.NoError(t, err)
+	iface := f.Declarations[2].(*ast.Interface)
+	require.Equal(t, "Record", iface.Member("x").Type.(*ast.TypeName).Name)
+	y := iface.Member("y")
+	require.True(t, y.Readonly)
+	require.Equal(t, "Sequence", y.Type.(*ast.NullableType).Type.(*ast.TypeName).Name)
+	op := iface.Member("f")
+	require.Equal(t, "Any", op.Type.(*ast.UnionType).Types[1].(*ast.TypeName).Name)
+	require.Equal(t, "Sequence", op.Parameters[0].Type.(*ast.TypeName).Name)
+}
+
+func TestRecoverErrors(t *testing.T) {
+	const src = `interface Foo {
+	attribute long x
+	attribute long y;
+};
+garbage;
+dictionary Bar {
+	long z garbage;
+	long w;
+};
+interface Baz {};`
+	f, err := ParseWith(src, Options{})
+	require.Error(t, err)
+	require.Len(t, f.Declarations, 1)
+
+	f, err = ParseWith(src, Options{RecoverErrors: true})
+	require.Error(t, err)
+	errs := err.(ErrorList)
+	var lines []int
+	for _, e := range errs {
+		lines = append(lines, e.Line)
+	}
+	require.Equal(t, []int{3, 5, 7}, lines, errs.Error())
+	require.Len(t, f.Declarations, 3)
+	require.Equal(t, "y", f.Declarations[0].(*ast.Interface).Members[1].(*ast.Member).Name)
+	require.Len(t, f.Declarations[1].(*ast.Dictionary).Members, 2)
+}
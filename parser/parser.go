@@ -8,17 +8,20 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dennwc/webidl/ast"
 )
 
 // tryConsumeIdentifier attempts to consume an expected identifier.
+// A leading underscore is stripped from the identifier, since it's only used
+// to escape names that would otherwise be keywords, like _object.
 func (p *sourceParser) tryConsumeIdentifier() (string, bool) {
 	if !p.isToken(tokenTypeIdentifier) {
 		return "", false
 	}
 
-	value := p.currentToken.value
+	value := strings.TrimPrefix(p.currentToken.value, "_")
 	p.consumeToken()
 	return value, true
 }
@@ -36,14 +39,23 @@ func (p *sourceParser) consumeIdentifier() string {
 func (p *sourceParser) consumeLiteral() ast.Literal {
 	base := &ast.Base{}
 	finish := p.node(base)
-	l, ok := p.consume(tokenTypeIdentifier, tokenTypeString, tokenTypeNumber, tokenTypeLeftBracket)
+	l, ok := p.consume(tokenTypeIdentifier, tokenTypeString, tokenTypeNumber, tokenTypeLeftBracket, tokenTypeLeftBrace)
 	if !ok {
 		p.emitError("Expected literal, found token %v", p.currentToken)
 		finish()
 		return &ast.BasicLiteral{Base: *base}
 	}
 	switch l.kind {
-	case tokenTypeIdentifier, tokenTypeString, tokenTypeNumber:
+	case tokenTypeIdentifier:
+		finish()
+		switch l.value {
+		case "null":
+			return &ast.NullLiteral{Base: *base}
+		case "true", "false":
+			return &ast.BoolLiteral{Base: *base, Value: l.value == "true"}
+		}
+		return &ast.BasicLiteral{Base: *base, Value: l.value}
+	case tokenTypeString, tokenTypeNumber:
 		finish()
 		return &ast.BasicLiteral{Base: *base, Value: l.value}
 	case tokenTypeLeftBracket:
@@ -63,6 +75,11 @@ func (p *sourceParser) consumeLiteral() ast.Literal {
 		finish()
 		n.Base = *base
 		return n
+	case tokenTypeLeftBrace:
+		// only an empty dictionary is allowed
+		p.consume(tokenTypeRightBrace)
+		finish()
+		return &ast.DictionaryLiteral{Base: *base}
 	}
 	panic("unreachable")
 }
@@ -82,11 +99,14 @@ type rightNodeConstructor func(ast.Node, lexeme) (ast.Node, bool)
 type commentedLexeme struct {
 	lexeme
 	comments []string
+	detached int // number of leading comments separated from the token by a blank line
+	trailing int // number of leading comments on the same line as the previous token
 }
 
 // sourceParser holds the state of the parser.
 type sourceParser struct {
 	startIndex    bytePosition    // The start index for position decoration on nodes.
+	startLine     lineNumber      // The line offset for position decoration on nodes.
 	lex           *peekableLexer  // a reference to the lexer used for tokenization
 	nodes         nodeStack       // the stack of the current nodes
 	currentToken  commentedLexeme // the current token
@@ -97,6 +117,12 @@ type sourceParser struct {
 // parserConfig holds configuration for customizing the parser
 type parserConfig struct {
 	ignoredTokenTypes map[tokenType]struct{} // the token types ignored by the parser
+	extraKeywords     map[string]struct{}    // dialect keywords that start a top-level declaration
+	declHandlers      []DeclHandler          // handlers for dialect-specific declarations
+	deprecations      DeprecationMode        // the way deprecated syntax is reported
+	keepSource        bool                   // retain the source text in the File node
+	ignoreKeywordCase bool                   // match keywords case-insensitively
+	recoverErrors     bool                   // skip to the next member or declaration after errors
 }
 
 // buildParser returns a new sourceParser instance.
@@ -144,7 +170,7 @@ func (p *sourceParser) node(node ast.Node) func() {
 func (p *sourceParser) decorateStartRuneAndComments(node ast.Node, token commentedLexeme) {
 	b := node.NodeBase()
 	b.Start = int(token.position) + int(p.startIndex)
-	b.Line = int(token.line)
+	b.Line = int(token.line) + int(p.startLine)
 	p.decorateComments(node, token.comments)
 }
 
@@ -152,12 +178,50 @@ func (p *sourceParser) decorateStartRuneAndComments(node ast.Node, token comment
 func (p *sourceParser) decorateComments(node ast.Node, comments []string) {
 	b := node.NodeBase()
 	b.Comments = append(b.Comments, comments...)
+	for i := len(comments) - 1; i >= 0; i-- {
+		if isDocComment(comments[i]) {
+			b.Doc = parseDocComment(comments[i])
+			break
+		}
+	}
+}
+
+// takeTrailingComments moves comments on the same line as the previous token from
+// the current token to the node. It is called after the node and its separator,
+// like a semicolon or a comma, are consumed.
+func (p *sourceParser) takeTrailingComments(n ast.Node) {
+	k := p.currentToken.trailing
+	if k == 0 {
+		return
+	}
+	b := n.NodeBase()
+	b.Trailing = append(b.Trailing, p.currentToken.comments[:k]...)
+	p.currentToken.comments = p.currentToken.comments[k:]
+	p.currentToken.trailing = 0
+	if p.currentToken.detached -= k; p.currentToken.detached < 0 {
+		p.currentToken.detached = 0
+	}
+}
+
+// takeDanglingComments moves comments of the current token to the node. It is used for
+// comments at the end of the node body that don't precede any other node.
+func (p *sourceParser) takeDanglingComments(n ast.Node) {
+	if len(p.currentToken.comments) == 0 {
+		return
+	}
+	b := n.NodeBase()
+	b.Dangling = append(b.Dangling, p.currentToken.comments...)
+	p.currentToken.comments = nil
+	p.currentToken.detached = 0
+	p.currentToken.trailing = 0
 }
 
 // decorateEndRune decorates the given node with the location of the given token as its
 // ending rune.
 func (p *sourceParser) decorateEndRune(node ast.Node, token commentedLexeme) {
-	node.NodeBase().End = int(token.position) + len(token.value) - 1 + int(p.startIndex)
+	b := node.NodeBase()
+	b.End = int(token.position) + len(token.value) - 1 + int(p.startIndex)
+	b.EndLine = int(token.line) + int(p.startLine)
 }
 
 // currentNode returns the node at the top of the stack.
@@ -168,17 +232,32 @@ func (p *sourceParser) currentNode() ast.Node {
 // consumeToken advances the lexer forward, returning the next token.
 func (p *sourceParser) consumeToken() commentedLexeme {
 	var comments = make([]string, 0)
+	detached, trailing, newlines := 0, 0, 0
+	sameLine := true
 
 	for {
 		token := p.lex.nextToken()
 
-		if token.kind == tokenTypeComment {
+		switch token.kind {
+		case tokenTypeComment:
 			comments = append(comments, token.value)
+			newlines = 0
+			if sameLine {
+				trailing = len(comments)
+			}
+		case tokenTypeWhitespace:
+			if token.value == "\n" {
+				sameLine = false
+				newlines++
+				if newlines == 2 {
+					detached = len(comments)
+				}
+			}
 		}
 
 		if _, ok := p.config.ignoredTokenTypes[token.kind]; !ok {
 			p.previousToken = p.currentToken
-			p.currentToken = commentedLexeme{token, comments}
+			p.currentToken = commentedLexeme{token, comments, detached, trailing}
 			return p.currentToken
 		}
 	}
@@ -198,10 +277,17 @@ func (p *sourceParser) isToken(types ...tokenType) bool {
 // nextToken returns the next token found, without advancing the parser. Used for
 // lookahead.
 func (p *sourceParser) nextToken() lexeme {
-	for i := 0; i < 1000; i++ {
+	return p.peekToken(1)
+}
+
+// peekToken returns the n-th token after the current one, without advancing the parser.
+func (p *sourceParser) peekToken(n int) lexeme {
+	for i := 0; i < 1000*n; i++ {
 		token := p.lex.peekToken(i + 1)
 		if _, ok := p.config.ignoredTokenTypes[token.kind]; !ok {
-			return token
+			if n--; n == 0 {
+				return token
+			}
 		}
 	}
 	panic("stale")
@@ -221,13 +307,63 @@ func (p *sourceParser) isNextToken(types ...tokenType) bool {
 }
 
 func (p *sourceParser) isIdentifier(name string) bool {
-	return p.isToken(tokenTypeIdentifier) && p.currentToken.value == name
+	return p.isToken(tokenTypeIdentifier) && p.keywordEqual(p.currentToken.value, name)
 }
 
 // isNextIdentifier returns true if the next token is a keyword matching that given.
 func (p *sourceParser) isNextIdentifier(keyword string) bool {
 	token := p.nextToken()
-	return token.kind == tokenTypeIdentifier && token.value == keyword
+	return token.kind == tokenTypeIdentifier && p.keywordEqual(token.value, keyword)
+}
+
+// structuralKeywords are keywords of declarations and members, which are matched
+// case-insensitively if the parser is configured to ignore keyword case. Keywords of types,
+// like sequence or record, are always case-sensitive, since they can't be told apart from
+// user-defined types named the same way.
+var structuralKeywords = map[string]struct{}{
+	"async":       {},
+	"attribute":   {},
+	"callback":    {},
+	"const":       {},
+	"constructor": {},
+	"deleter":     {},
+	"dictionary":  {},
+	"enum":        {},
+	"getter":      {},
+	"implements":  {},
+	"includes":    {},
+	"inherit":     {},
+	"interface":   {},
+	"iterable":    {},
+	"jsonifier":   {},
+	"maplike":     {},
+	"mixin":       {},
+	"namespace":   {},
+	"optional":    {},
+	"partial":     {},
+	"readonly":    {},
+	"required":    {},
+	"serializer":  {},
+	"setlike":     {},
+	"setter":      {},
+	"static":      {},
+	"stringifier": {},
+	"typedef":     {},
+}
+
+// keywordEqual checks if the token value matches the keyword, according to the parser configuration.
+func (p *sourceParser) keywordEqual(value, keyword string) bool {
+	if value == keyword {
+		return true
+	}
+	if !p.config.ignoreKeywordCase {
+		return false
+	}
+	_, structural := structuralKeywords[keyword]
+	if !structural {
+		_, structural = p.config.extraKeywords[keyword]
+	}
+	return structural && strings.EqualFold(value, keyword)
 }
 
 // emitError creates a new error node and attachs it as a child of the current
@@ -238,6 +374,44 @@ func (p *sourceParser) emitError(format string, args ...interface{}) {
 	b.Errors = append(b.Errors, errorNode)
 }
 
+// emitNodeError adds an error node spanning a node that was already consumed.
+func (p *sourceParser) emitNodeError(n ast.Node, format string, args ...interface{}) {
+	b := n.NodeBase()
+	errorNode := &ast.ErrorNode{Message: fmt.Sprintf(format, args...)}
+	errorNode.Start, errorNode.End = b.Start, b.End
+	errorNode.Line, errorNode.EndLine = b.Line, b.EndLine
+	b.Errors = append(b.Errors, errorNode)
+}
+
+// emitWarning creates a new error node with a warning severity and attaches it as
+// a child of the current node.
+func (p *sourceParser) emitWarning(format string, args ...interface{}) {
+	errorNode := p.createErrorNode(format, args...)
+	errorNode.Severity = ast.SeverityWarning
+	b := p.currentNode().NodeBase()
+	b.Errors = append(b.Errors, errorNode)
+}
+
+// emitDeprecated reports usage of deprecated syntax, according to the parser configuration.
+func (p *sourceParser) emitDeprecated(format string, args ...interface{}) {
+	switch p.config.deprecations {
+	case DeprecationsWarn:
+		p.emitWarning(format, args...)
+	case DeprecationsError:
+		p.emitError(format, args...)
+	}
+}
+
+// emitTrailingComma reports a trailing comma in a list that doesn't allow it, like an argument
+// list. Such commas are accepted with a warning in lenient mode, and are errors otherwise.
+func (p *sourceParser) emitTrailingComma(list string) {
+	if p.config.deprecations == DeprecationsWarn {
+		p.emitWarning("Trailing comma is not allowed in %s", list)
+	} else {
+		p.emitError("Trailing comma is not allowed in %s", list)
+	}
+}
+
 // consumeKeyword consumes an expected keyword token or adds an error node.
 func (p *sourceParser) consumeKeyword(keyword string) bool {
 	if !p.tryConsumeKeyword(keyword) {
@@ -262,7 +436,9 @@ func (p *sourceParser) tryConsumeKeyword(keyword string) bool {
 func (p *sourceParser) consume(types ...tokenType) (lexeme, bool) {
 	token, ok := p.tryConsume(types...)
 	if !ok {
-		p.emitError("Expected one of: %v, found: %v", types, p.currentToken)
+		// copy the list, so it doesn't escape to the heap on the hot path
+		expected := append([]tokenType(nil), types...)
+		p.emitError("Expected one of: %v, found: %v", expected, p.currentToken)
 	}
 	return token, ok
 }
@@ -283,7 +459,7 @@ func (p *sourceParser) tryConsumeWithComments(types ...tokenType) (commentedLexe
 		return token, true
 	}
 
-	return commentedLexeme{lexeme{tokenTypeError, -1, -1, ""}, make([]string, 0)}, false
+	return commentedLexeme{lexeme: lexeme{tokenTypeError, -1, -1, ""}, comments: make([]string, 0)}, false
 }
 
 // consumeUntil consumes all tokens until one of the given token types is found.
@@ -4,24 +4,11 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/dennwc/webidl/ast"
 )
 
-// Parse parses the given WebIDL source into a parse tree.
-func Parse(input string) *ast.File {
-	lexer := lex(input)
-
-	config := parserConfig{
-		ignoredTokenTypes: map[tokenType]struct{}{
-			tokenTypeWhitespace: {},
-			tokenTypeComment:    {},
-		},
-	}
-
-	parser := buildParser(lexer, config, bytePosition(0))
-	return parser.consumeTopLevel()
-}
-
 // consumeTopLevel attempts to consume the top-level constructs of a WebIDL file.
 func (p *sourceParser) consumeTopLevel() *ast.File {
 	n := &ast.File{}
@@ -35,38 +22,60 @@ func (p *sourceParser) consumeTopLevel() *ast.File {
 		return n
 	}
 
+	// Comments separated from the first declaration by a blank line belong to the file,
+	// like a license header.
+	k := p.currentToken.detached
+	if p.isToken(tokenTypeEOF) {
+		k = len(p.currentToken.comments)
+	}
+	if k > 0 {
+		p.decorateComments(n, p.currentToken.comments[:k])
+		p.currentToken.comments = p.currentToken.comments[k:]
+		p.currentToken.detached = 0
+	}
+
+	add := func(d ast.Decl) {
+		n.Declarations = append(n.Declarations, d)
+		p.takeTrailingComments(d)
+	}
 Loop:
 	for !p.isToken(tokenTypeEOF) {
 		switch {
+		case p.isInclusion("implements"):
+			// checked first, so names that look like keywords are not treated as declarations
+			add(p.consumeImplementation())
+			continue
+		case p.isInclusion("includes"):
+			add(p.consumeIncludes())
+			continue
 		case p.isToken(tokenTypeLeftBracket) || p.isIdentifier("interface") ||
 			p.isIdentifier("partial") || p.isIdentifier("callback") ||
-			p.isIdentifier("dictionary") || p.isIdentifier("enum") ||
-			p.isIdentifier("typedef"):
-			n.Declarations = append(n.Declarations, p.consumeDeclaration())
+			p.isIdentifier("dictionary") || p.isIdentifier("namespace") || p.isIdentifier("enum") ||
+			p.isIdentifier("typedef") || p.isExtraKeyword():
+			add(p.consumeDeclaration())
 			continue
-		case p.isToken(tokenTypeIdentifier):
-			name := p.consumeIdentifier()
-			if p.tryConsumeKeyword("implements") {
-				impl := &ast.Implementation{Name: name}
-				impl.Source = p.consumeIdentifier()
-				n.Declarations = append(n.Declarations, impl)
-				p.consume(tokenTypeSemicolon)
-				continue
-			} else if p.tryConsumeKeyword("includes") {
-				impl := &ast.Includes{Name: name}
-				impl.Source = p.consumeIdentifier()
-				n.Declarations = append(n.Declarations, impl)
-				p.consume(tokenTypeSemicolon)
-				continue
-			}
 		}
 		p.emitError("Unexpected token at root level: %v", p.currentToken)
-		break Loop
+		if !p.config.recoverErrors {
+			break Loop
+		}
+		p.skipToDeclEnd()
+	}
+	if p.isToken(tokenTypeEOF) {
+		// comments after the last declaration
+		p.takeDanglingComments(n)
 	}
 
 	return n
 }
 
+// isInclusion checks if the current tokens start an includes or implements statement:
+// A includes B. Declarations named like the keyword, like "interface includes {}", are not matched.
+func (p *sourceParser) isInclusion(keyword string) bool {
+	return p.isToken(tokenTypeIdentifier) && p.isNextIdentifier(keyword) &&
+		p.peekToken(2).kind == tokenTypeIdentifier
+}
+
 func (p *sourceParser) consumeInterfaceOrMixin(ann []*ast.Annotation, base *ast.Base, finish func()) ast.Decl {
 	partial := p.tryConsumeKeyword("partial")
 	p.consumeKeyword("interface")
@@ -83,9 +92,26 @@ func (p *sourceParser) consumeInterface(partial, callback bool, ann []*ast.Annot
 	}()
 
 	n.Name = p.consumeIdentifier()
+	p.checkBuiltinName(n.Name)
 
 	if _, ok := p.tryConsume(tokenTypeColon); ok {
 		n.Inherits = p.consumeIdentifier()
+		if partial {
+			p.emitError("Partial interface %s cannot inherit from %s, only the primary declaration can", n.Name, n.Inherits)
+		}
+		if p.isToken(tokenTypeComma) {
+			p.emitError("Interface %s can only inherit from a single interface", n.Name)
+			// skip the rest of the list
+			for p.isToken(tokenTypeComma, tokenTypeIdentifier) {
+				p.consumeToken()
+			}
+		}
+	}
+
+	// ; (forward declaration)
+	if _, ok := p.tryConsume(tokenTypeSemicolon); ok {
+		n.Forward = true
+		return n
 	}
 
 	// {
@@ -93,7 +119,7 @@ func (p *sourceParser) consumeInterface(partial, callback bool, ann []*ast.Annot
 
 loop:
 	for {
-		if p.isToken(tokenTypeRightBrace) {
+		if p.isToken(tokenTypeRightBrace, tokenTypeEOF) {
 			break
 		}
 
@@ -113,40 +139,104 @@ loop:
 			if !ok {
 				break loop
 			}
+			p.takeTrailingComments(op)
 
 			continue
-		} else if p.isIdentifier("iterable") {
-			p.consume(tokenTypeIdentifier)
-			iter := &ast.Iterable{}
-			finish := p.node(iter)
-			p.consume(tokenTypeLeftTri)
-			iter.Elem = p.consumeType()
-			if _, ok := p.tryConsume(tokenTypeComma); ok {
-				iter.Key = iter.Elem
-				iter.Elem = p.consumeType()
-			}
-			p.consume(tokenTypeRightTri)
-			finish()
-			n.Iterable = iter
+		} else if p.isIdentifier("iterable") || p.isIdentifier("async") && p.isNextIdentifier("iterable") {
+			n.Iterable = p.consumeIterable()
 			_, ok := p.consume(tokenTypeSemicolon)
 			if !ok {
 				break loop
 			}
+			p.takeTrailingComments(n.Iterable)
 
 			continue
+		} else if p.isIdentifier("maplike") || p.isIdentifier("readonly") && p.isNextIdentifier("maplike") {
+			n.Maplike = p.consumeMaplike()
+			if _, ok := p.consume(tokenTypeSemicolon); !ok {
+				break loop
+			}
+			p.takeTrailingComments(n.Maplike)
+			continue
+		} else if p.isIdentifier("setlike") || p.isIdentifier("readonly") && p.isNextIdentifier("setlike") {
+			n.Setlike = p.consumeSetlike()
+			if _, ok := p.consume(tokenTypeSemicolon); !ok {
+				break loop
+			}
+			p.takeTrailingComments(n.Setlike)
+			continue
+		}
+		m := p.consumeInterfaceMember()
+		if c, ok := m.(*ast.Constructor); ok && n.Callback {
+			p.emitNodeError(c, "Callback interfaces cannot have constructors")
 		}
-		n.Members = append(n.Members, p.consumeInterfaceMember())
+		n.Members = append(n.Members, m)
 
-		if _, ok := p.consume(tokenTypeSemicolon); !ok {
-			p.emitError("Expected semicolon, got: %v", p.currentToken)
+		if !p.consumeMemberEnd(m) {
 			break
 		}
 	}
 
 	// };
-	p.consume(tokenTypeRightBrace)
-	p.consume(tokenTypeSemicolon)
+	p.consumeBodyEnd("interface")
+
+	return n
+}
 
+// consumeIterable consumes an iterable declaration: [async] iterable<K, V>, with optional
+// arguments for async iterables.
+func (p *sourceParser) consumeIterable() *ast.Iterable {
+	async := p.tryConsumeKeyword("async")
+	p.consumeKeyword("iterable")
+	n := &ast.Iterable{Async: async}
+	defer p.node(n)()
+	p.consume(tokenTypeLeftTri)
+	n.Elem = p.consumeType()
+	if _, ok := p.tryConsume(tokenTypeComma); ok {
+		n.Key = n.Elem
+		n.Elem = p.consumeType()
+	}
+	p.consume(tokenTypeRightTri)
+	if n.Async && p.isToken(tokenTypeLeftParen) {
+		n.Parameters = p.consumeParameters()
+		for _, arg := range n.Parameters {
+			if !arg.Optional {
+				p.emitNodeError(arg, "Async iterable argument %s must be optional", arg.Name)
+			}
+		}
+	}
+	return n
+}
+
+// consumeMaplike consumes a maplike declaration: readonly maplike<K, V>.
+func (p *sourceParser) consumeMaplike() *ast.Maplike {
+	n := &ast.Maplike{}
+	defer p.node(n)()
+	n.Readonly = p.tryConsumeKeyword("readonly")
+	p.consumeKeyword("maplike")
+	p.consume(tokenTypeLeftTri)
+	n.Key = p.consumeType()
+	p.consume(tokenTypeComma)
+	n.Elem = p.consumeType()
+	p.consume(tokenTypeRightTri)
+	if p.tryConsumeKeyword("readonly") {
+		p.emitError("Readonly modifier must precede maplike")
+	}
+	return n
+}
+
+// consumeSetlike consumes a setlike declaration: readonly setlike<T>.
+func (p *sourceParser) consumeSetlike() *ast.Setlike {
+	n := &ast.Setlike{}
+	defer p.node(n)()
+	n.Readonly = p.tryConsumeKeyword("readonly")
+	p.consumeKeyword("setlike")
+	p.consume(tokenTypeLeftTri)
+	n.Elem = p.consumeType()
+	p.consume(tokenTypeRightTri)
+	if p.tryConsumeKeyword("readonly") {
+		p.emitError("Readonly modifier must precede setlike")
+	}
 	return n
 }
 
@@ -168,7 +258,7 @@ func (p *sourceParser) consumeMixin(partial bool, ann []*ast.Annotation, base *a
 
 loop:
 	for {
-		if p.isToken(tokenTypeRightBrace) {
+		if p.isToken(tokenTypeRightBrace, tokenTypeEOF) {
 			break
 		}
 
@@ -186,6 +276,7 @@ loop:
 			if !ok {
 				break loop
 			}
+			p.takeTrailingComments(customOpNode)
 
 			continue
 		} else if p.isIdentifier("iterable") {
@@ -201,20 +292,20 @@ loop:
 			if !ok {
 				break loop
 			}
+			p.takeTrailingComments(iter)
 
 			continue
 		}
-		n.Members = append(n.Members, p.consumeMixinMember())
+		m := p.consumeMixinMember()
+		n.Members = append(n.Members, m)
 
-		if _, ok := p.consume(tokenTypeSemicolon); !ok {
-			p.emitError("Expected semicolon, got: %v", p.currentToken)
+		if !p.consumeMemberEnd(m) {
 			break
 		}
 	}
 
 	// };
-	p.consume(tokenTypeRightBrace)
-	p.consume(tokenTypeSemicolon)
+	p.consumeBodyEnd("interface mixin")
 
 	return n
 }
@@ -229,27 +320,85 @@ func (p *sourceParser) consumeDictionary(ann []*ast.Annotation, base *ast.Base,
 	p.consumeKeyword("dictionary")
 
 	n.Name = p.consumeIdentifier()
+	p.checkBuiltinName(n.Name)
 	if _, ok := p.tryConsume(tokenTypeColon); ok {
 		n.Inherits = p.consumeIdentifier()
+		if n.Partial {
+			p.emitError("Partial dictionary %s cannot inherit from %s, only the primary declaration can", n.Name, n.Inherits)
+		}
 	}
 
 	// {
 	p.consume(tokenTypeLeftBrace)
-	for !p.isToken(tokenTypeRightBrace) {
-		n.Members = append(n.Members, p.consumeMember(true))
+	for !p.isToken(tokenTypeRightBrace, tokenTypeEOF) {
+		m := p.consumeDictionaryMember()
+		n.Members = append(n.Members, m)
 
-		if _, ok := p.consume(tokenTypeSemicolon); !ok {
-			p.emitError("Expected semicolon, got: %v", p.currentToken)
+		if !p.consumeMemberEnd(m) {
 			break
 		}
 	}
 
 	// };
-	p.consume(tokenTypeRightBrace)
-	p.consume(tokenTypeSemicolon)
+	p.consumeBodyEnd("dictionary")
+	return n
+}
+
+func (p *sourceParser) consumeNamespace(ann []*ast.Annotation, base *ast.Base, finish func()) *ast.Namespace {
+	n := &ast.Namespace{Annotations: ann}
+	defer func() {
+		finish()
+		n.Base = *base
+	}()
+	n.Partial = p.tryConsumeKeyword("partial")
+	p.consumeKeyword("namespace")
+
+	n.Name = p.consumeIdentifier()
+
+	// {
+	p.consume(tokenTypeLeftBrace)
+	for !p.isToken(tokenTypeRightBrace, tokenTypeEOF) {
+		m := p.consumeNamespaceMember()
+		n.Members = append(n.Members, m)
+
+		if !p.consumeMemberEnd(m) {
+			break
+		}
+	}
+
+	// };
+	p.consumeBodyEnd("namespace")
 	return n
 }
 
+// consumeNamespaceMember consumes a namespace member. Namespaces may only contain
+// constants, readonly attributes and regular operations.
+func (p *sourceParser) consumeNamespaceMember() *ast.Member {
+	base := &ast.Base{}
+	finish := p.node(base)
+	ann := p.consumeNodeAnnotations(base)
+	m := p.consumeMember(false, ann, base, finish)
+	switch {
+	case m.Attribute && !m.Readonly:
+		p.emitNodeError(m, "Namespace attribute %s must be readonly", m.Name)
+	case m.Static:
+		p.emitNodeError(m, "Namespace member %s cannot be static", m.Name)
+	case m.Specialization != "":
+		p.emitNodeError(m, "Namespace operation %s cannot be a %s", m.Name, m.Specialization)
+	}
+	return m
+}
+
+// checkBuiltinName reports a warning if the declaration name shadows a built-in type.
+func (p *sourceParser) checkBuiltinName(name string) {
+	switch (&ast.TypeName{Name: name}).Kind() {
+	case ast.KindReference, ast.KindDate, ast.KindException:
+		// not built in, or declared in IDL by the specifications themselves
+		return
+	}
+	p.emitWarning("Declaration %s shadows a built-in type", name)
+}
+
 func (p *sourceParser) consumeTypedef(ann []*ast.Annotation, base *ast.Base, finish func()) *ast.Typedef {
 	n := &ast.Typedef{Annotations: ann}
 	defer func() {
@@ -259,6 +408,7 @@ func (p *sourceParser) consumeTypedef(ann []*ast.Annotation, base *ast.Base, fin
 	p.consumeKeyword("typedef")
 	n.Type = p.consumeType()
 	n.Name = p.consumeIdentifier()
+	p.checkBuiltinName(n.Name)
 	p.consume(tokenTypeSemicolon)
 	return n
 }
@@ -273,23 +423,36 @@ func (p *sourceParser) consumeEnum(ann []*ast.Annotation, base *ast.Base, finish
 
 	// {
 	p.consume(tokenTypeLeftBrace)
-	for !p.isToken(tokenTypeRightBrace) {
+	for !p.isToken(tokenTypeRightBrace, tokenTypeEOF) {
 		if len(n.Values) != 0 {
 			// ,
 			if _, ok := p.tryConsume(tokenTypeComma); !ok {
 				break
 			}
+			p.takeTrailingComments(n.Values[len(n.Values)-1])
 		}
-		if p.isToken(tokenTypeRightBrace) {
+		if p.isToken(tokenTypeRightBrace, tokenTypeEOF) {
 			break
 		}
-		n.Values = append(n.Values, p.consumeLiteral())
+		n.Values = append(n.Values, p.consumeEnumValue())
 	}
 	// , (optional)
 	p.tryConsume(tokenTypeComma)
+	if len(n.Values) != 0 {
+		p.takeTrailingComments(n.Values[len(n.Values)-1])
+	}
 	// };
-	p.consume(tokenTypeRightBrace)
-	p.consume(tokenTypeSemicolon)
+	p.consumeBodyEnd("enum")
+	return n
+}
+
+// consumeEnumValue consumes a single enum value with optional annotations.
+func (p *sourceParser) consumeEnumValue() *ast.EnumValue {
+	n := &ast.EnumValue{}
+	defer p.node(n)()
+
+	n.Annotations = p.tryConsumeAnnotations()
+	n.Value = p.consumeLiteral()
 	return n
 }
 
@@ -297,7 +460,7 @@ func (p *sourceParser) consumeEnum(ann []*ast.Annotation, base *ast.Base, finish
 func (p *sourceParser) consumeDeclaration() ast.Decl {
 	base := &ast.Base{}
 	finish := p.node(base)
-	ann := p.tryConsumeAnnotations()
+	ann := p.consumeNodeAnnotations(base)
 	switch {
 	case p.isIdentifier("enum"):
 		return p.consumeEnum(ann, base, finish)
@@ -314,16 +477,24 @@ func (p *sourceParser) consumeDeclaration() ast.Decl {
 		par := p.consumeParameters()
 		p.consume(tokenTypeSemicolon)
 		finish()
-		return &ast.Callback{Base: *base, Name: name, Return: ret, Parameters: par}
+		return &ast.Callback{Base: *base, Annotations: ann, Name: name, Return: ret, Parameters: par}
 	case p.isIdentifier("interface"):
 		return p.consumeInterfaceOrMixin(ann, base, finish)
 	case p.isIdentifier("dictionary"):
 		return p.consumeDictionary(ann, base, finish)
+	case p.isIdentifier("namespace"):
+		return p.consumeNamespace(ann, base, finish)
 	case p.isIdentifier("partial"):
 		if p.isNextIdentifier("interface") {
 			return p.consumeInterfaceOrMixin(ann, base, finish)
 		} else if p.isNextIdentifier("dictionary") {
 			return p.consumeDictionary(ann, base, finish)
+		} else if p.isNextIdentifier("namespace") {
+			return p.consumeNamespace(ann, base, finish)
+		}
+	case p.isExtraKeyword():
+		if n, ok := p.tryConsumeDialectDecl(ann, base, finish); ok {
+			return n
 		}
 	}
 	p.emitError("Expected interface or dictionary, got: %v", p.currentToken)
@@ -341,42 +512,128 @@ func (p *sourceParser) consumeDeclaration() ast.Decl {
 }
 
 func (p *sourceParser) consumeInterfaceMember() ast.InterfaceMember {
-	return p.consumeMember(false)
+	base := &ast.Base{}
+	finish := p.node(base)
+	ann := p.consumeNodeAnnotations(base)
+	if p.isConstructor() {
+		return p.consumeConstructor(ann, base, finish)
+	}
+	return p.consumeMember(false, ann, base, finish)
 }
 
 func (p *sourceParser) consumeMixinMember() ast.MixinMember {
-	return p.consumeMember(false)
+	base := &ast.Base{}
+	finish := p.node(base)
+	ann := p.consumeNodeAnnotations(base)
+	if p.isConstructor() {
+		p.emitError("Mixins cannot have constructors")
+		return p.consumeConstructor(ann, base, finish)
+	}
+	return p.consumeMember(false, ann, base, finish)
+}
+
+func (p *sourceParser) consumeDictionaryMember() *ast.Member {
+	base := &ast.Base{}
+	finish := p.node(base)
+	ann := p.consumeNodeAnnotations(base)
+	return p.consumeMember(true, ann, base, finish)
+}
+
+// isConstructor checks if the current token starts a constructor operation.
+func (p *sourceParser) isConstructor() bool {
+	return p.isIdentifier("constructor") && p.isNextToken(tokenTypeLeftParen)
+}
+
+// consumeConstructor consumes a constructor operation: constructor(...).
+func (p *sourceParser) consumeConstructor(ann []*ast.Annotation, base *ast.Base, finish func()) *ast.Constructor {
+	n := &ast.Constructor{Annotations: ann}
+	defer func() {
+		finish()
+		n.Base = *base
+	}()
+	p.consumeKeyword("constructor")
+	n.Parameters = p.consumeParameters()
+	return n
 }
 
 // consumeMember attempts to consume a member definition in a declaration.
-func (p *sourceParser) consumeMember(dict bool) *ast.Member {
-	n := &ast.Member{}
-	defer p.node(n)()
+func (p *sourceParser) consumeMember(dict bool, ann []*ast.Annotation, base *ast.Base, finish func()) *ast.Member {
+	n := &ast.Member{Annotations: ann}
+	defer func() {
+		finish()
+		n.Base = *base
+	}()
 
-	n.Annotations = p.tryConsumeAnnotations()
 	n.Attribute = dict
 
+	// optional is only valid for parameters; report it and skip the member
+	if !dict && p.isIdentifier("optional") {
+		p.emitError("Optional modifier is only allowed on parameters, not on members")
+		p.skipToMemberEnd()
+		return n
+	}
+
+	// annotations may also appear after any of the modifiers, they are all attached to the member
+	moreAnnotations := func() {
+		n.Annotations = append(n.Annotations, p.tryConsumeAnnotations()...)
+	}
+
+	// inherit is only a keyword for attributes, otherwise it's a type name
+	if p.isIdentifier("inherit") && (p.isNextIdentifier("attribute") || p.isNextIdentifier("readonly")) {
+		p.consumeToken()
+		n.Inherit = true
+	}
+
 	// getter/setter
 	if p.isIdentifier("getter") || p.isIdentifier("setter") || p.isIdentifier("deleter") {
-		n.Specialization = p.consumeIdentifier()
+		n.Specialization = strings.ToLower(p.consumeIdentifier())
 	} else if p.tryConsumeKeyword("stringifier") {
 		n.Specialization = "stringifier"
 	}
+	moreAnnotations()
 
 	if p.tryConsumeKeyword("const") {
 		n.Const = true
+		moreAnnotations()
 	}
 
 	if p.tryConsumeKeyword("static") {
 		n.Static = true
+		moreAnnotations()
 	}
 
 	if p.tryConsumeKeyword("readonly") {
 		n.Readonly = true
+		moreAnnotations()
 	}
 
 	if p.tryConsumeKeyword("required") {
 		n.Required = true
+		moreAnnotations()
+	}
+
+	if p.isIdentifier("inherit") && p.isNextIdentifier("attribute") {
+		p.emitError("Inherit modifier must precede other modifiers")
+		p.consumeToken()
+		n.Inherit = true
+	} else if n.Inherit && n.Readonly {
+		p.emitError("Inherited attribute cannot be readonly")
+	}
+
+	// modifiers of interface members are shared with dictionaries, but not allowed there
+	if dict {
+		for _, mod := range []struct {
+			set  bool
+			name string
+		}{
+			{n.Const, "const"},
+			{n.Static, "static"},
+			{n.Readonly, "readonly"},
+		} {
+			if mod.set {
+				p.emitError("Dictionary member cannot be %s", mod.name)
+			}
+		}
 	}
 
 	if p.tryConsumeKeyword("attribute") {
@@ -385,7 +642,7 @@ func (p *sourceParser) consumeMember(dict bool) *ast.Member {
 
 	// second annotation place, and in in HTML specification,
 	// annotation is gived on both places on some members
-	n.Annotations = append(n.Annotations, p.tryConsumeAnnotations()...)
+	moreAnnotations()
 
 	// Consume the type of the member.
 	n.Type = p.consumeType()
@@ -393,14 +650,106 @@ func (p *sourceParser) consumeMember(dict bool) *ast.Member {
 	// Consume the member's name.
 	n.Name, _ = p.tryConsumeIdentifier()
 
+	// An operation without a return type, e.g. foo(); - the type we consumed is the name.
+	if tn, ok := n.Type.(*ast.TypeName); ok && n.Name == "" && n.Specialization == "" &&
+		!n.Attribute && !n.Const && p.isToken(tokenTypeLeftParen) {
+		p.emitError("Missing return type or attribute keyword for member %s", tn.Name)
+		n.Name, n.Type = tn.Name, nil
+		p.skipToMemberEnd()
+		return n
+	}
+
 	// If not an attribute, consume the parameters of the member.
 	if !n.Attribute && !n.Const {
 		n.Parameters = p.consumeParameters()
 	}
 	n.Init = p.tryConsumeDefaultValue()
+	if n.Init != nil && !dict && !n.Const {
+		// only dictionary members and constants have values
+		kind := "Operation"
+		if n.Attribute {
+			kind = "Attribute"
+		}
+		p.emitNodeError(n.Init, "%s %s cannot have a default value", kind, n.Name)
+	}
 	return n
 }
 
+// consumeMemberEnd consumes the semicolon that ends a member. In the recovery mode,
+// the rest of a malformed member is skipped, so the next member can be parsed.
+// If the next token is on a new line, the semicolon is assumed to be missing.
+// It returns false if parsing of the declaration body should stop.
+func (p *sourceParser) consumeMemberEnd(m ast.Node) bool {
+	if _, ok := p.tryConsume(tokenTypeSemicolon); ok {
+		p.takeTrailingComments(m)
+		return true
+	}
+	p.emitError("Expected semicolon, got: %v", p.currentToken)
+	if !p.config.recoverErrors {
+		return false
+	}
+	if p.currentToken.line > p.previousToken.line {
+		// likely a missing semicolon at the end of the line, the next member starts here
+		return true
+	}
+	p.skipToMemberEnd()
+	p.tryConsume(tokenTypeSemicolon)
+	return true
+}
+
+// skipToDeclEnd consumes all tokens until the semicolon that ends a top-level declaration,
+// including the semicolon. Declaration bodies are skipped as a whole.
+func (p *sourceParser) skipToDeclEnd() {
+	depth := 0
+	for !p.isToken(tokenTypeEOF) {
+		switch {
+		case p.isToken(tokenTypeLeftBrace):
+			depth++
+		case p.isToken(tokenTypeRightBrace):
+			if depth > 0 {
+				depth--
+			}
+		case p.isToken(tokenTypeSemicolon) && depth == 0:
+			p.consumeToken()
+			return
+		}
+		p.consumeToken()
+	}
+}
+
+// consumeBodyEnd consumes the closing brace and the semicolon of a declaration body.
+// If the input ends before the closing brace, a single error is reported instead.
+func (p *sourceParser) consumeBodyEnd(kind string) {
+	if p.isToken(tokenTypeEOF) {
+		p.emitError("Unterminated %s body", kind)
+		return
+	}
+	if p.isToken(tokenTypeRightBrace) {
+		p.takeDanglingComments(p.currentNode())
+	}
+	p.consume(tokenTypeRightBrace)
+	p.consume(tokenTypeSemicolon)
+}
+
+// skipToMemberEnd consumes all tokens until the semicolon that ends the member.
+// It stops on the closing brace, so the body of the declaration is never left.
+func (p *sourceParser) skipToMemberEnd() {
+	for !p.isToken(tokenTypeSemicolon, tokenTypeRightBrace, tokenTypeEOF) {
+		p.consumeToken()
+	}
+}
+
+// consumeNodeAnnotations consumes annotations preceding the node. Comments found between
+// the annotations and the rest of the node are attached to the node as well.
+func (p *sourceParser) consumeNodeAnnotations(n ast.Node) []*ast.Annotation {
+	start := p.currentToken.position
+	ann := p.tryConsumeAnnotations()
+	if p.currentToken.position != start {
+		p.decorateComments(n, p.currentToken.comments)
+	}
+	return ann
+}
+
 // tryConsumeAnnotations consumes any annotations found on the parent node.
 func (p *sourceParser) tryConsumeAnnotations() (out []*ast.Annotation) {
 	for {
@@ -409,6 +758,11 @@ func (p *sourceParser) tryConsumeAnnotations() (out []*ast.Annotation) {
 			return
 		}
 
+		// ] (empty set, as found in generated IDL)
+		if _, ok := p.tryConsume(tokenTypeRightBracket); ok {
+			continue
+		}
+
 		for {
 			// Foo()
 			out = append(out, p.consumeAnnotationPart())
@@ -417,6 +771,10 @@ func (p *sourceParser) tryConsumeAnnotations() (out []*ast.Annotation) {
 			if _, ok := p.tryConsume(tokenTypeComma); !ok {
 				break
 			}
+			if p.isToken(tokenTypeRightBracket) {
+				p.emitTrailingComma("an extended attribute list")
+				break
+			}
 		}
 
 		// ]
@@ -443,7 +801,13 @@ func (p *sourceParser) consumeAnnotationPart() *ast.Annotation {
 			n.Values = list
 		} else {
 			n.Value = p.consumeIdentifier()
-			if p.isToken(tokenTypeLeftParen) {
+			if p.isToken(tokenTypePipe) {
+				// some vendor attributes use [A=B|C], which is not valid WebIDL
+				p.emitError("Alternative values separated by | are not supported, use a list instead: %s=(a, b)", n.Name)
+				for p.isToken(tokenTypePipe, tokenTypeIdentifier) {
+					p.consumeToken()
+				}
+			} else if p.isToken(tokenTypeLeftParen) {
 				// Consume (optional) parameters, e.g. NamedConstructor=Foo(...).
 				n.Parameters = p.consumeParameters()
 			}
@@ -470,6 +834,10 @@ func (p *sourceParser) tryConsumeIdentifiersList() ([]string, bool) {
 		if _, ok := p.tryConsume(tokenTypeComma); !ok {
 			break
 		}
+		if p.isToken(tokenTypeRightParen) {
+			p.emitTrailingComma("an identifier list")
+			break
+		}
 	}
 	// ")"
 	p.consume(tokenTypeRightParen)
@@ -485,7 +853,100 @@ var expandedTypeKeywords = map[string][]string{
 	"unrestricted":  {"float", "double"},
 }
 
-func (p *sourceParser) consumeType() (otyp ast.Type) {
+// expandedTypeNames maps a prefix and a secondary keyword to the expanded type name.
+// Names are precomputed, so parsing of types doesn't allocate them each time.
+var expandedTypeNames = func() map[[2]string]string {
+	m := make(map[[2]string]string)
+	for prefix, secondaries := range expandedTypeKeywords {
+		for _, secondary := range secondaries {
+			m[[2]string{prefix, secondary}] = prefix + " " + secondary
+		}
+	}
+	return m
+}()
+
+// incompleteTypeKeywords is a set of expanded type prefixes that are not valid types on their own.
+var incompleteTypeKeywords = map[string]struct{}{
+	"unsigned":     {},
+	"unrestricted": {},
+}
+
+// recordKeyTypes is a set of types allowed as record keys.
+var recordKeyTypes = map[string]struct{}{
+	"DOMString":  {},
+	"USVString":  {},
+	"ByteString": {},
+}
+
+// isRecordKeyType checks if a type can be used as a record key.
+func isRecordKeyType(t ast.Type) bool {
+	tn, ok := t.(*ast.TypeName)
+	if !ok {
+		return false
+	}
+	_, ok = recordKeyTypes[tn.Name]
+	return ok
+}
+
+// typeAnnotations is a set of extended attributes that apply to types rather than
+// to parameters or members.
+var typeAnnotations = map[string]struct{}{
+	"AllowShared":             {},
+	"Clamp":                   {},
+	"EnforceRange":            {},
+	"LegacyNullToEmptyString": {},
+	"TreatNullAs":             {},
+}
+
+// consumeType consumes a type with optional extended attributes.
+func (p *sourceParser) consumeType() ast.Type {
+	if !p.isToken(tokenTypeLeftBracket) {
+		return p.consumeBareType()
+	}
+	ann := p.tryConsumeAnnotations()
+	typ := p.consumeBareType()
+	if !attachTypeAnnotations(typ, ann) {
+		p.emitError("Extended attributes are only supported on named types")
+	}
+	return typ
+}
+
+// attachTypeAnnotations adds extended attributes to the named type.
+// It returns false if the type cannot have extended attributes.
+func attachTypeAnnotations(typ ast.Type, ann []*ast.Annotation) bool {
+	if len(ann) == 0 {
+		return true
+	}
+	if nt, ok := typ.(*ast.NullableType); ok {
+		typ = nt.Type
+	}
+	tn, ok := typ.(*ast.TypeName)
+	if !ok {
+		return false
+	}
+	tn.Annotations = append(tn.Annotations, ann...)
+	return true
+}
+
+// moveTypeAnnotations moves extended attributes that apply to types from the list
+// to the given type. It returns remaining annotations.
+func moveTypeAnnotations(typ ast.Type, list []*ast.Annotation) []*ast.Annotation {
+	var out, move []*ast.Annotation
+	for _, a := range list {
+		if _, ok := typeAnnotations[a.Name]; ok {
+			move = append(move, a)
+		} else {
+			out = append(out, a)
+		}
+	}
+	if len(move) == 0 || !attachTypeAnnotations(typ, move) {
+		return list
+	}
+	return out
+}
+
+// consumeBareType consumes a type without extended attributes.
+func (p *sourceParser) consumeBareType() (otyp ast.Type) {
 	base := &ast.Base{}
 	finish := p.node(base)
 	defer func() {
@@ -496,6 +957,7 @@ func (p *sourceParser) consumeType() (otyp ast.Type) {
 		*otyp.NodeBase() = *base
 		if _, ok := p.tryConsume(tokenTypeQuestionMark); ok {
 			nl := &ast.NullableType{Base: *base, Type: otyp}
+			nl.Errors = nil // already attached to the inner type
 			nl.End++
 			otyp = nl
 		}
@@ -512,6 +974,9 @@ func (p *sourceParser) consumeType() (otyp ast.Type) {
 		rec := &ast.RecordType{}
 		p.consume(tokenTypeLeftTri)
 		rec.Key = p.consumeType()
+		if !isRecordKeyType(rec.Key) {
+			p.emitError("Record key must be DOMString, USVString or ByteString")
+		}
 		p.consume(tokenTypeComma)
 		rec.Elem = p.consumeType()
 		p.consume(tokenTypeRightTri)
@@ -535,19 +1000,25 @@ loop:
 	for {
 		// If the identifier is the beginning of a possible expanded type name, check for the
 		// secondary portion.
+		if !p.isToken(tokenTypeIdentifier) {
+			break
+		}
 		secondaries, ok := expandedTypeKeywords[typeName]
 		if !ok {
 			break
 		}
 		for _, secondary := range secondaries {
-			if p.isToken(tokenTypeIdentifier) && p.currentToken.value == secondary {
-				typeName += " " + secondary
-				p.consume(tokenTypeIdentifier)
+			if p.currentToken.value == secondary {
+				typeName = expandedTypeNames[[2]string{typeName, secondary}]
+				p.consumeToken()
 				continue loop
 			}
 		}
 		break
 	}
+	if _, ok := incompleteTypeKeywords[typeName]; ok {
+		p.emitError("Type %s must be followed by %s", typeName, strings.Join(expandedTypeKeywords[typeName], " or "))
+	}
 	if _, ok := p.tryConsume(tokenTypeLeftTri); ok {
 		pr := &ast.ParametrizedType{Name: typeName}
 		for !p.isToken(tokenTypeRightTri) {
@@ -564,6 +1035,9 @@ loop:
 		p.consume(tokenTypeRightTri)
 		return pr
 	}
+	if typeName == "void" {
+		p.emitDeprecated("Void type is deprecated, use undefined instead")
+	}
 	return &ast.TypeName{Name: typeName}
 }
 
@@ -583,6 +1057,7 @@ func (p *sourceParser) consumeParameter() *ast.Parameter {
 
 	// Consume the parameter's type.
 	n.Type = p.consumeType()
+	n.Annotations = moveTypeAnnotations(n.Type, n.Annotations)
 	if _, ok := p.tryConsume(tokenTypeVariadic); ok {
 		n.Variadic = true
 	}
@@ -616,6 +1091,7 @@ func (p *sourceParser) consumeParameters() (out []*ast.Parameter) {
 
 	for {
 		out = append(out, p.consumeParameter())
+		p.checkParameterOrder(out)
 		if _, ok := p.tryConsume(tokenTypeRightParen); ok {
 			return
 		}
@@ -623,6 +1099,25 @@ func (p *sourceParser) consumeParameters() (out []*ast.Parameter) {
 		if _, ok := p.consume(tokenTypeComma); !ok {
 			return
 		}
+		if p.isToken(tokenTypeRightParen) {
+			p.emitTrailingComma("an argument list")
+			p.consumeToken()
+			return
+		}
+	}
+}
+
+// checkParameterOrder checks that the last parameter in the list can follow the previous ones:
+// required parameters must precede optional ones, and a variadic parameter must be the last one.
+func (p *sourceParser) checkParameterOrder(list []*ast.Parameter) {
+	if len(list) < 2 {
+		return
+	}
+	last, prev := list[len(list)-1], list[len(list)-2]
+	if prev.Variadic {
+		p.emitNodeError(prev, "Variadic parameter %s must be the last one", prev.Name)
+	} else if prev.Optional && !last.Optional && !last.Variadic {
+		p.emitNodeError(last, "Required parameter %s cannot follow an optional parameter", last.Name)
 	}
 }
 
@@ -638,6 +1133,7 @@ func (p *sourceParser) consumeImplementation() *ast.Implementation {
 	if !p.consumeKeyword("implements") {
 		return n
 	}
+	p.emitDeprecated("Implements statement is deprecated, use includes instead")
 
 	// identifier
 	n.Source = p.consumeIdentifier()
@@ -654,7 +1150,7 @@ func (p *sourceParser) consumeIncludes() *ast.Includes {
 	// identifier
 	n.Name = p.consumeIdentifier()
 
-	// implements
+	// includes
 	if !p.consumeKeyword("includes") {
 		return n
 	}
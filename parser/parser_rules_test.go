@@ -0,0 +1,958 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+// parseDecl parses a source with a single declaration and returns it.
+func parseDecl(t *testing.T, src string) ast.Decl {
+	f := Parse(src)
+	require.Empty(t, f.Errors)
+	require.Len(t, f.Declarations, 1)
+	return f.Declarations[0]
+}
+
+func TestRecordKeyType(t *testing.T) {
+	d := parseDecl(t, `typedef record<DOMString, long> Valid;`)
+	rec, ok := d.(*ast.Typedef).Type.(*ast.RecordType)
+	require.True(t, ok)
+	require.Empty(t, rec.Errors)
+
+	d = parseDecl(t, `typedef record<long, long> Invalid;`)
+	rec, ok = d.(*ast.Typedef).Type.(*ast.RecordType)
+	require.True(t, ok)
+	require.Len(t, rec.Errors, 1)
+	require.Contains(t, rec.Errors[0].Message, "Record key")
+	require.Equal(t, "long", rec.Key.(*ast.TypeName).Name)
+	require.Equal(t, "long", rec.Elem.(*ast.TypeName).Name)
+}
+
+func TestAnnotationParameters(t *testing.T) {
+	d := parseDecl(t, `[SomeAttr(optional long x = 0, DOMString... rest), LegacyFactoryFunction=Foo(DOMString s)]
+interface Foo {};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, iface.Errors)
+	require.Len(t, iface.Annotations, 2)
+
+	a := iface.Annotations[0]
+	require.Equal(t, "SomeAttr", a.Name)
+	require.Empty(t, a.Errors)
+	require.Len(t, a.Parameters, 2)
+
+	x := a.Parameters[0]
+	require.Equal(t, "x", x.Name)
+	require.True(t, x.Optional)
+	require.False(t, x.Variadic)
+	require.Equal(t, "0", x.Init.(*ast.BasicLiteral).Value)
+
+	rest := a.Parameters[1]
+	require.Equal(t, "rest", rest.Name)
+	require.False(t, rest.Optional)
+	require.True(t, rest.Variadic)
+	require.Nil(t, rest.Init)
+
+	a = iface.Annotations[1]
+	require.Equal(t, "LegacyFactoryFunction", a.Name)
+	require.Equal(t, "Foo", a.Value)
+	require.Len(t, a.Parameters, 1)
+	require.Equal(t, "s", a.Parameters[0].Name)
+}
+
+func TestDeprecatedSyntax(t *testing.T) {
+	const src = `callback Foo = void ();
+Window implements Bar;`
+
+	// errors of a given severity in the tree
+	problems := func(f *ast.File, sev ast.Severity) (out []string) {
+		ast.Walk(f, func(n ast.Node) bool {
+			for _, e := range n.NodeBase().Errors {
+				if e.Severity == sev {
+					out = append(out, e.Message)
+				}
+			}
+			return true
+		})
+		return out
+	}
+
+	f := Parse(src)
+	require.Empty(t, problems(f, ast.SeverityError))
+	require.Empty(t, f.Warnings())
+
+	f = ParseLenient(src)
+	require.Empty(t, problems(f, ast.SeverityError))
+	require.Len(t, f.Warnings(), 2)
+	require.Contains(t, f.Warnings()[0].Message, "Void")
+	require.Len(t, f.Declarations, 2)
+
+	f = ParseStrict(src)
+	require.Len(t, problems(f, ast.SeverityError), 2)
+	require.Empty(t, f.Warnings())
+}
+
+func TestTrailingCommas(t *testing.T) {
+	const src = `[Exposed=(Window,), Foo,]
+interface Foo {
+	undefined f(long a, long b,);
+};
+enum E { "a", "b", };`
+
+	_, err := ParseErr(src)
+	require.Error(t, err)
+	require.Len(t, err.(ErrorList), 3)
+
+	f := ParseLenient(src)
+	require.Empty(t, ast.AllErrors(f))
+	warns := f.Warnings()
+	require.Len(t, warns, 3)
+	require.Equal(t, "Trailing comma is not allowed in an identifier list", warns[0].Message)
+	require.Equal(t, "Trailing comma is not allowed in an extended attribute list", warns[1].Message)
+	require.Equal(t, "Trailing comma is not allowed in an argument list", warns[2].Message)
+
+	iface := f.Declarations[0].(*ast.Interface)
+	require.Len(t, iface.Annotations, 2)
+	require.Equal(t, []string{"Window"}, iface.Annotations[0].Values)
+	op := iface.Members[0].(*ast.Member)
+	require.Len(t, op.Parameters, 2)
+	require.Len(t, f.Declarations[1].(*ast.Enum).Values, 2)
+}
+
+func TestConstructor(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	constructor(long x);
+	attribute long constructor;
+};`)
+	iface := d.(*ast.Interface)
+	require.Len(t, iface.Members, 2)
+	c, ok := iface.Members[0].(*ast.Constructor)
+	require.True(t, ok)
+	require.Empty(t, c.Errors)
+	require.Len(t, c.Parameters, 1)
+	require.Equal(t, "constructor", iface.Member("constructor").Name)
+
+	d = parseDecl(t, `interface mixin Foo {
+	constructor(long x);
+};`)
+	mixin := d.(*ast.Mixin)
+	require.Len(t, mixin.Members, 1)
+	c, ok = mixin.Members[0].(*ast.Constructor)
+	require.True(t, ok)
+	require.Len(t, c.Errors, 1)
+	require.Contains(t, c.Errors[0].Message, "Mixins cannot have constructors")
+
+	d = parseDecl(t, `callback interface Foo {
+	constructor();
+	void handleEvent();
+};`)
+	iface = d.(*ast.Interface)
+	require.True(t, iface.Callback)
+	require.Len(t, iface.Members, 2)
+	c, ok = iface.Members[0].(*ast.Constructor)
+	require.True(t, ok)
+	require.Len(t, ast.AllErrors(iface), 1)
+	require.Len(t, c.Errors, 1)
+	require.Equal(t, "Callback interfaces cannot have constructors", c.Errors[0].Message)
+}
+
+func TestConstructorAnnotations(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	[Throws] constructor(DOMString x);
+	// the default one
+	[Throws, SecureContext] [CEReactions] constructor();
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.Len(t, iface.Members, 2)
+	var names [][]string
+	for _, m := range iface.Members {
+		c, ok := m.(*ast.Constructor)
+		require.True(t, ok)
+		var list []string
+		for _, a := range c.Annotations {
+			list = append(list, a.Name)
+		}
+		names = append(names, list)
+	}
+	require.Equal(t, [][]string{{"Throws"}, {"Throws", "SecureContext", "CEReactions"}}, names)
+	c := iface.Members[1].(*ast.Constructor)
+	require.Equal(t, 4, c.Line)
+	require.Equal(t, []string{"// the default one"}, c.Comments)
+
+	var walked []string
+	ast.Walk(c, func(n ast.Node) bool {
+		if a, ok := n.(*ast.Annotation); ok {
+			walked = append(walked, a.Name)
+		}
+		return true
+	})
+	require.Equal(t, names[1], walked)
+}
+
+func TestIncludesStatement(t *testing.T) {
+	f := Parse(`DOMException includes Serializable;
+Window implements WindowEventHandlers;
+interface Foo {};
+`)
+	require.Empty(t, f.Errors)
+	require.Len(t, f.Declarations, 3)
+
+	inc, ok := f.Declarations[0].(*ast.Includes)
+	require.True(t, ok)
+	require.Equal(t, "DOMException", inc.Name)
+	require.Equal(t, "Serializable", inc.Source)
+	require.Empty(t, inc.Errors)
+	require.Equal(t, 0, inc.Start)
+	require.Equal(t, 34, inc.End)
+
+	impl, ok := f.Declarations[1].(*ast.Implementation)
+	require.True(t, ok)
+	require.Equal(t, "Window", impl.Name)
+	require.Equal(t, "WindowEventHandlers", impl.Source)
+	require.Empty(t, impl.Errors)
+	require.Equal(t, 2, impl.Line)
+
+	_, ok = f.Declarations[2].(*ast.Interface)
+	require.True(t, ok)
+
+	f = Parse(`Foo includes Bar interface Baz {};`)
+	inc = f.Declarations[0].(*ast.Includes)
+	require.Len(t, inc.Errors, 1)
+}
+
+func TestEnumValueAnnotations(t *testing.T) {
+	d := parseDecl(t, `enum Foo { [Deprecated] "a", "b" };`)
+	enum := d.(*ast.Enum)
+	require.Empty(t, enum.Errors)
+	require.Len(t, enum.Values, 2)
+
+	a := enum.Values[0]
+	require.Equal(t, `"a"`, a.Raw())
+	require.Len(t, a.Annotations, 1)
+	require.Equal(t, "Deprecated", a.Annotations[0].Name)
+	require.Equal(t, 11, a.Start)
+
+	b := enum.Values[1]
+	require.Equal(t, `"b"`, b.Raw())
+	require.Empty(t, b.Annotations)
+}
+
+func TestEnumAnnotations(t *testing.T) {
+	d := parseDecl(t, `// Modes.
+[Exposed=Window, SecureContext] enum Mode { "a" };`)
+	enum := d.(*ast.Enum)
+	require.Empty(t, ast.AllErrors(enum))
+	require.Equal(t, "Mode", enum.Name)
+	require.Len(t, enum.Annotations, 2)
+	require.Equal(t, "Exposed", enum.Annotations[0].Name)
+	require.Equal(t, "Window", enum.Annotations[0].Value)
+	require.Equal(t, "SecureContext", enum.Annotations[1].Name)
+	require.Equal(t, []string{"// Modes."}, enum.Comments)
+	require.Equal(t, 2, enum.Line)
+	require.Len(t, enum.Values, 1)
+	require.Empty(t, enum.Values[0].Annotations)
+}
+
+func TestCallbackPromise(t *testing.T) {
+	d := parseDecl(t, `callback Fn = Promise<undefined> (long x);`)
+	cb := d.(*ast.Callback)
+	require.Empty(t, cb.Errors)
+	require.Equal(t, "Fn", cb.Name)
+
+	ret, ok := cb.Return.(*ast.ParametrizedType)
+	require.True(t, ok)
+	require.Equal(t, "Promise", ret.Name)
+	require.Len(t, ret.Elems, 1)
+	require.Equal(t, "undefined", ret.Elems[0].(*ast.TypeName).Name)
+
+	require.Len(t, cb.Parameters, 1)
+	require.Equal(t, "x", cb.Parameters[0].Name)
+	require.Equal(t, "long", cb.Parameters[0].Type.(*ast.TypeName).Name)
+}
+
+func TestInterfaceForward(t *testing.T) {
+	f := Parse(`interface Foo;
+interface Bar : Foo;
+interface Baz {};`)
+	require.Empty(t, ast.AllErrors(f))
+	require.Len(t, f.Declarations, 3)
+
+	foo := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "Foo", foo.Name)
+	require.True(t, foo.Forward)
+	require.Equal(t, 13, foo.End)
+
+	bar := f.Declarations[1].(*ast.Interface)
+	require.True(t, bar.Forward)
+	require.Equal(t, "Foo", bar.Inherits)
+
+	baz := f.Declarations[2].(*ast.Interface)
+	require.False(t, baz.Forward)
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	d := parseDecl(t, `interface WebGLRenderingContext {
+	undefined bufferData(GLenum target, [AllowShared] ArrayBufferView data);
+	undefined setValues([Foo] sequence<[Clamp] octet> values);
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(d))
+
+	data := iface.Member("bufferData").Parameters[1]
+	require.Empty(t, data.Annotations)
+	typ := data.Type.(*ast.TypeName)
+	require.Equal(t, "ArrayBufferView", typ.Name)
+	require.NotNil(t, typ.Annotation("AllowShared"))
+	require.Nil(t, typ.Annotation("Clamp"))
+
+	values := iface.Member("setValues").Parameters[0]
+	require.Len(t, values.Annotations, 1)
+	require.Equal(t, "Foo", values.Annotations[0].Name)
+	elem := values.Type.(*ast.SequenceType).Elem.(*ast.TypeName)
+	require.NotNil(t, elem.Annotation("Clamp"))
+}
+
+func TestNullLiteral(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	void f(optional Foo? x = null, optional DOMString s = "null");
+};`)
+	params := d.(*ast.Interface).Member("f").Parameters
+	require.Len(t, params, 2)
+	require.IsType(t, &ast.NullLiteral{}, params[0].Init)
+	require.IsType(t, &ast.NullableType{}, params[0].Type)
+	require.Equal(t, `"null"`, params[1].Init.(*ast.BasicLiteral).Value)
+}
+
+func TestBoolLiteral(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	void f(optional boolean a = true, optional boolean b = false);
+};`)
+	params := d.(*ast.Interface).Member("f").Parameters
+	require.Len(t, params, 2)
+
+	a, ok := params[0].Init.(*ast.BoolLiteral)
+	require.True(t, ok)
+	require.True(t, a.Value)
+	require.Equal(t, "true", a.String())
+
+	b, ok := params[1].Init.(*ast.BoolLiteral)
+	require.True(t, ok)
+	require.False(t, b.Value)
+	require.Equal(t, "false", b.String())
+}
+
+func TestEnumUnusualValues(t *testing.T) {
+	d := parseDecl(t, `enum Foo { "", "a-b", "with space" };`)
+	enum := d.(*ast.Enum)
+	require.Empty(t, ast.AllErrors(enum))
+	var values []string
+	for _, v := range enum.Values {
+		values = append(values, v.Raw())
+	}
+	require.Equal(t, []string{`""`, `"a-b"`, `"with space"`}, values)
+}
+
+func TestMissingReturnType(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	foo(long x, (long or DOMString) y);
+	attribute long x;
+};`)
+	iface := d.(*ast.Interface)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Missing return type or attribute keyword for member foo", errs[0].Message)
+	require.Equal(t, 2, errs[0].Line)
+
+	require.Len(t, iface.Members, 2)
+	foo := iface.Member("foo")
+	require.NotNil(t, foo)
+	require.Nil(t, foo.Type)
+	require.NotNil(t, iface.Member("x"))
+}
+
+func TestOptionalMember(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	optional long x;
+	attribute long y;
+};`)
+	iface := d.(*ast.Interface)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Message, "Optional modifier is only allowed on parameters")
+	require.Len(t, iface.Members, 2)
+	require.NotNil(t, iface.Member("y"))
+}
+
+func TestNamespace(t *testing.T) {
+	d := parseDecl(t, `[Exposed=Window] namespace Console {
+	const long MAX = 10;
+	readonly attribute long count;
+	void log(any... data);
+};`)
+	ns := d.(*ast.Namespace)
+	require.Equal(t, "Console", ns.Name)
+	require.False(t, ns.Partial)
+	require.Len(t, ns.Annotations, 1)
+	require.Len(t, ns.Members, 3)
+	require.True(t, ns.Members[0].Const)
+	require.True(t, ns.Members[1].Readonly)
+	require.Equal(t, "log", ns.Members[2].Name)
+	require.Empty(t, ast.AllErrors(ns))
+
+	d = parseDecl(t, `partial namespace Console { const long MIN = 0; };`)
+	ns = d.(*ast.Namespace)
+	require.True(t, ns.Partial)
+	require.Len(t, ns.Members, 1)
+	require.Empty(t, ast.AllErrors(ns))
+}
+
+func TestNamespaceMutableAttribute(t *testing.T) {
+	d := parseDecl(t, `namespace Console {
+	attribute long count;
+	static void log();
+};`)
+	ns := d.(*ast.Namespace)
+	require.Len(t, ns.Members, 2)
+	errs := ast.AllErrors(ns)
+	require.Len(t, errs, 2)
+	require.Equal(t, "Namespace attribute count must be readonly", errs[0].Message)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, "Namespace member log cannot be static", errs[1].Message)
+}
+
+func TestMaplikeSetlike(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	readonly maplike<DOMString, long>;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.NotNil(t, iface.Maplike)
+	require.True(t, iface.Maplike.Readonly)
+	require.Equal(t, "DOMString", iface.Maplike.Key.(*ast.TypeName).Name)
+	require.Equal(t, "long", iface.Maplike.Elem.(*ast.TypeName).Name)
+
+	d = parseDecl(t, `interface Bar {
+	setlike<DOMString>;
+	attribute long size;
+};`)
+	iface = d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.NotNil(t, iface.Setlike)
+	require.False(t, iface.Setlike.Readonly)
+	require.Len(t, iface.Members, 1)
+}
+
+func TestTrailingReadonly(t *testing.T) {
+	for _, src := range []string{
+		`interface Foo { maplike<DOMString, long> readonly; };`,
+		`interface Foo { setlike<long> readonly; };`,
+	} {
+		d := parseDecl(t, src)
+		errs := ast.AllErrors(d)
+		require.Len(t, errs, 1, src)
+		require.Contains(t, errs[0].Message, "Readonly modifier must precede")
+	}
+}
+
+func TestParameterOrder(t *testing.T) {
+	errors := func(src string) (out []string) {
+		for _, e := range ast.AllErrors(parseDecl(t, src)) {
+			out = append(out, e.Message)
+		}
+		return out
+	}
+	require.Empty(t, errors(`interface Foo { void f(long a, optional long b, long... rest); };`))
+	require.Empty(t, errors(`interface Foo { void f(optional long a, optional long b); };`))
+	require.Equal(t, []string{"Required parameter b cannot follow an optional parameter"},
+		errors(`interface Foo { void f(optional long a, long b); };`))
+	require.Equal(t, []string{"Variadic parameter a must be the last one"},
+		errors(`interface Foo { void f(long... a, optional long b); };`))
+}
+
+func TestMultipleInheritance(t *testing.T) {
+	d := parseDecl(t, `interface A : B, C {
+	attribute long x;
+};`)
+	iface := d.(*ast.Interface)
+	require.Equal(t, "B", iface.Inherits)
+	require.NotNil(t, iface.Member("x"))
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Interface A can only inherit from a single interface", errs[0].Message)
+}
+
+func TestAnnotationGroups(t *testing.T) {
+	d := parseDecl(t, `[C][D, E] interface Foo {
+	[A][B] readonly attribute long x;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	names := func(list []*ast.Annotation) (out []string) {
+		for _, a := range list {
+			out = append(out, a.Name)
+		}
+		return out
+	}
+	require.Equal(t, []string{"C", "D", "E"}, names(iface.Annotations))
+	require.Equal(t, []string{"A", "B"}, names(iface.Member("x").Annotations))
+}
+
+func TestAnnotationValues(t *testing.T) {
+	d := parseDecl(t, `[CachedAttribute=(a, b), Custom=Setter, Exposed=(Window)] interface Foo {};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.Len(t, iface.Annotations, 3)
+	require.Equal(t, []string{"a", "b"}, iface.Annotations[0].Values)
+	require.Equal(t, "Setter", iface.Annotations[1].Value)
+	require.Equal(t, []string{"Window"}, iface.Annotations[2].Values)
+
+	d = parseDecl(t, `[Custom=Getter|Setter, SecureContext] interface Foo {};`)
+	iface = d.(*ast.Interface)
+	require.Len(t, iface.Annotations, 2)
+	require.Equal(t, "Getter", iface.Annotations[0].Value)
+	require.Equal(t, "SecureContext", iface.Annotations[1].Name)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Alternative values separated by | are not supported, use a list instead: Custom=(a, b)", errs[0].Message)
+}
+
+func TestUnionReturnType(t *testing.T) {
+	d := parseDecl(t, `interface Storage {
+	(long or DOMString) get(DOMString key);
+	static (Node or sequence<Node>)? find();
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+
+	m := iface.Member("get")
+	require.NotNil(t, m)
+	require.False(t, m.Attribute)
+	u, ok := m.Type.(*ast.UnionType)
+	require.True(t, ok)
+	require.Len(t, u.Types, 2)
+	require.Len(t, m.Parameters, 1)
+	require.Equal(t, "key", m.Parameters[0].Name)
+
+	m = iface.Member("find")
+	require.NotNil(t, m)
+	require.True(t, m.Static)
+	nt, ok := m.Type.(*ast.NullableType)
+	require.True(t, ok)
+	require.IsType(t, &ast.UnionType{}, nt.Type)
+	require.Empty(t, m.Parameters)
+}
+
+func TestBuiltinNameShadowing(t *testing.T) {
+	f, err := ParseErr(`typedef (Foo or Bar) DOMString;
+interface long {};
+dictionary Options {};
+interface DOMException {};`)
+	// warnings are not errors
+	require.NoError(t, err)
+	warns := f.Warnings()
+	require.Len(t, warns, 2)
+	require.Equal(t, "Declaration DOMString shadows a built-in type", warns[0].Message)
+	require.Equal(t, 1, warns[0].Line)
+	require.Equal(t, "Declaration long shadows a built-in type", warns[1].Message)
+}
+
+func TestEmptyAnnotations(t *testing.T) {
+	d := parseDecl(t, `[] interface Foo {
+	[][A] attribute long x;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.Empty(t, iface.Annotations)
+	require.Len(t, iface.Member("x").Annotations, 1)
+}
+
+func TestPartialMixin(t *testing.T) {
+	d := parseDecl(t, `partial interface mixin Foo {
+	attribute long x;
+};`)
+	mixin, ok := d.(*ast.Mixin)
+	require.True(t, ok)
+	require.Empty(t, ast.AllErrors(mixin))
+	require.True(t, mixin.Partial)
+	require.Equal(t, "Foo", mixin.Name)
+	require.Len(t, mixin.Members, 1)
+
+	d = parseDecl(t, `interface mixin Foo {};`)
+	require.False(t, d.(*ast.Mixin).Partial)
+}
+
+func TestCommentAfterAnnotations(t *testing.T) {
+	d := parseDecl(t, `// leading
+[Exposed=Window] // main thread only
+interface Foo {
+	[SameObject] /* after */ readonly attribute long x;
+};`)
+	iface := d.(*ast.Interface)
+	require.Equal(t, []string{"// leading", "// main thread only"}, iface.Comments)
+	require.Equal(t, []string{"/* after */"}, iface.Member("x").Comments)
+}
+
+func TestDictionaryMemberModifiers(t *testing.T) {
+	d := parseDecl(t, `dictionary Options {
+	readonly long x;
+	static long y;
+	required long z;
+};`)
+	dict := d.(*ast.Dictionary)
+	require.Len(t, dict.Members, 3)
+	errs := ast.AllErrors(dict)
+	require.Len(t, errs, 2)
+	require.Equal(t, "Dictionary member cannot be readonly", errs[0].Message)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, "Dictionary member cannot be static", errs[1].Message)
+	require.Len(t, dict.Members[0].Errors, 1)
+	require.Empty(t, dict.Members[2].Errors)
+}
+
+func TestNestedGenericDefault(t *testing.T) {
+	d := parseDecl(t, `dictionary Matrix {
+	sequence<sequence<long>> matrix = [];
+	record<DOMString, sequence<DOMString>> headers;
+};`)
+	dict := d.(*ast.Dictionary)
+	require.Empty(t, ast.AllErrors(dict))
+
+	m := dict.Member("matrix")
+	seq, ok := m.Type.(*ast.SequenceType)
+	require.True(t, ok)
+	require.IsType(t, &ast.SequenceType{}, seq.Elem)
+	init, ok := m.Init.(*ast.SequenceLiteral)
+	require.True(t, ok)
+	require.Empty(t, init.Elems)
+
+	rec, ok := dict.Member("headers").Type.(*ast.RecordType)
+	require.True(t, ok)
+	require.IsType(t, &ast.SequenceType{}, rec.Elem)
+}
+
+func TestAsyncIterable(t *testing.T) {
+	d := parseDecl(t, `interface ReadableStream {
+	async iterable<any>(optional ReadableStreamIteratorOptions options = {});
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.NotNil(t, iface.Iterable)
+	require.True(t, iface.Iterable.Async)
+	require.Nil(t, iface.Iterable.Key)
+	require.Len(t, iface.Iterable.Parameters, 1)
+
+	d = parseDecl(t, `interface Foo {
+	async iterable<DOMString, long>(Options options);
+};`)
+	iface = d.(*ast.Interface)
+	require.NotNil(t, iface.Iterable.Key)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Async iterable argument options must be optional", errs[0].Message)
+}
+
+func TestIncompleteTypeKeywords(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	attribute unsigned x;
+	attribute unrestricted y;
+	attribute unsigned short z;
+	attribute unrestricted double w;
+};`)
+	iface := d.(*ast.Interface)
+	require.Len(t, iface.Members, 4)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 2)
+	require.Equal(t, "Type unsigned must be followed by short or long", errs[0].Message)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, "Type unrestricted must be followed by float or double", errs[1].Message)
+	require.Equal(t, 3, errs[1].Line)
+	require.Equal(t, "unsigned short", iface.Members[2].(*ast.Member).Type.(*ast.TypeName).Name)
+}
+
+func TestExpandedTypeNames(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	attribute long long a;
+	attribute unsigned long long b;
+	attribute unsigned long c;
+	attribute long d;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	var names []string
+	for _, m := range iface.Members {
+		names = append(names, m.(*ast.Member).Type.(*ast.TypeName).Name)
+	}
+	require.Equal(t, []string{"long long", "unsigned long long", "unsigned long", "long"}, names)
+}
+
+func TestMemberAnnotationOrder(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	[SameObject] readonly attribute Node a;
+	static [Foo] long b();
+	static [Foo] readonly attribute long c;
+	[A] static readonly [B] attribute long d;
+	readonly attribute [C] long e;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	for _, c := range []struct {
+		name   string
+		static bool
+		ann    []string
+	}{
+		{"a", false, []string{"SameObject"}},
+		{"b", true, []string{"Foo"}},
+		{"c", true, []string{"Foo"}},
+		{"d", true, []string{"A", "B"}},
+		{"e", false, []string{"C"}},
+	} {
+		m := iface.Member(c.name)
+		require.NotNil(t, m, c.name)
+		require.Equal(t, c.static, m.Static, c.name)
+		var names []string
+		for _, a := range m.Annotations {
+			names = append(names, a.Name)
+		}
+		require.Equal(t, c.ann, names, c.name)
+		require.IsType(t, &ast.TypeName{}, m.Type, c.name)
+	}
+}
+
+func TestUnterminatedBody(t *testing.T) {
+	for _, c := range []struct {
+		src string
+		exp string
+	}{
+		{`interface Foo { attribute long x;`, "Unterminated interface body"},
+		{`interface mixin Foo { attribute long x; `, "Unterminated interface mixin body"},
+		{`dictionary Foo { long x;`, "Unterminated dictionary body"},
+		{`namespace Foo { readonly attribute long x;`, "Unterminated namespace body"},
+		{`enum Foo { "a", `, "Unterminated enum body"},
+		{`interface Foo {`, "Unterminated interface body"},
+	} {
+		f := Parse(c.src)
+		errs := ast.AllErrors(f)
+		require.Len(t, errs, 1, c.src)
+		require.Equal(t, c.exp, errs[0].Message, c.src)
+		require.Len(t, f.Declarations, 1, c.src)
+	}
+}
+
+func TestRecordTypedef(t *testing.T) {
+	d := parseDecl(t, `typedef record<ByteString, any> Headers;`)
+	td := d.(*ast.Typedef)
+	require.Empty(t, ast.AllErrors(td))
+	require.Equal(t, "Headers", td.Name)
+	rec, ok := td.Type.(*ast.RecordType)
+	require.True(t, ok)
+	require.Equal(t, "ByteString", rec.Key.(*ast.TypeName).Name)
+	require.IsType(t, &ast.AnyType{}, rec.Elem)
+
+	d = parseDecl(t, `typedef record<USVString, sequence<DOMString>>? OptionalHeaders;`)
+	td = d.(*ast.Typedef)
+	require.Empty(t, ast.AllErrors(td))
+	nt, ok := td.Type.(*ast.NullableType)
+	require.True(t, ok)
+	require.IsType(t, &ast.RecordType{}, nt.Type)
+}
+
+func TestEscapedIdentifiers(t *testing.T) {
+	d := parseDecl(t, `interface _Foo : _object {
+	attribute _long _interface;
+	void _setter(_Bar _optional);
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.Equal(t, "Foo", iface.Name)
+	require.Equal(t, "object", iface.Inherits)
+	attr := iface.Member("interface")
+	require.NotNil(t, attr)
+	require.Equal(t, "long", attr.Type.(*ast.TypeName).Name)
+	op := iface.Member("setter")
+	require.NotNil(t, op)
+	require.Empty(t, op.Specialization)
+	require.Equal(t, "Bar", op.Parameters[0].Type.(*ast.TypeName).Name)
+	require.Equal(t, "optional", op.Parameters[0].Name)
+	require.False(t, op.Parameters[0].Optional)
+}
+
+func TestKeywordLikeOperationNames(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	boolean includes(DOMString x);
+	long or(long a, long b);
+	(long or DOMString) async();
+	undefined iterable(optional long n);
+	attribute long maplike;
+	readonly attribute long setlike;
+	static Promise<undefined> implements(any x);
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.Nil(t, iface.Iterable)
+	require.Nil(t, iface.Maplike)
+	require.Nil(t, iface.Setlike)
+	var names []string
+	for _, m := range iface.Members {
+		names = append(names, m.(*ast.Member).Name)
+	}
+	require.Equal(t, []string{"includes", "or", "async", "iterable", "maplike", "setlike", "implements"}, names)
+	require.Len(t, iface.Member("or").Parameters, 2)
+	require.IsType(t, &ast.UnionType{}, iface.Member("async").Type)
+}
+
+func TestKeywordLikeDeclarationNames(t *testing.T) {
+	f, err := ParseErr(`interface includes {};
+dictionary implements {};
+callback includes = undefined ();
+Window includes includes;`)
+	require.NoError(t, err)
+	require.Len(t, f.Declarations, 4)
+	require.Equal(t, "includes", f.Declarations[0].(*ast.Interface).Name)
+	require.Equal(t, "implements", f.Declarations[1].(*ast.Dictionary).Name)
+	require.Equal(t, "includes", f.Declarations[2].(*ast.Callback).Name)
+	require.Equal(t, "includes", f.Declarations[3].(*ast.Includes).Source)
+}
+
+func TestPartialInheritance(t *testing.T) {
+	f := Parse(`interface Foo : Bar {};
+partial interface Foo : Baz {};
+dictionary Opts : Base {};
+partial dictionary Opts : Other {};`)
+	require.Len(t, f.Declarations, 4)
+	errs := ast.AllErrors(f)
+	require.Len(t, errs, 2)
+	require.Equal(t, "Partial interface Foo cannot inherit from Baz, only the primary declaration can", errs[0].Message)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, "Partial dictionary Opts cannot inherit from Other, only the primary declaration can", errs[1].Message)
+	require.Equal(t, "Baz", f.Declarations[1].(*ast.Interface).Inherits)
+}
+
+func TestStringTypeNames(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	attribute DOMString value;
+	attribute USVString url;
+	attribute ByteString long;
+	attribute DOMString? short;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	for _, c := range []struct{ name, typ string }{
+		{"value", "DOMString"},
+		{"url", "USVString"},
+		{"long", "ByteString"},
+	} {
+		m := iface.Member(c.name)
+		require.NotNil(t, m, c.name)
+		require.Equal(t, c.typ, m.Type.(*ast.TypeName).Name)
+	}
+	m := iface.Member("short")
+	require.NotNil(t, m)
+	require.Equal(t, "DOMString", m.Type.(*ast.NullableType).Type.(*ast.TypeName).Name)
+}
+
+func TestInterfaceWithoutMembers(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	iterable<Node>;
+	stringifier;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+	require.Empty(t, iface.Members)
+	require.NotNil(t, iface.Iterable)
+	require.Equal(t, "Node", iface.Iterable.Elem.(*ast.TypeName).Name)
+	require.Len(t, iface.CustomOps, 1)
+	require.Equal(t, "stringifier", iface.CustomOps[0].Name)
+	require.True(t, iface.HasStringifier())
+}
+
+func TestMemberDefaultValue(t *testing.T) {
+	d := parseDecl(t, `interface Foo {
+	attribute long x = 0;
+	const long C = 1;
+	void f() = null;
+};`)
+	iface := d.(*ast.Interface)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 2)
+	require.Equal(t, "Attribute x cannot have a default value", errs[0].Message)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, "Operation f cannot have a default value", errs[1].Message)
+	require.NotNil(t, iface.Member("x").Init)
+
+	d = parseDecl(t, `dictionary Opts {
+	long x = 0;
+};`)
+	require.Empty(t, ast.AllErrors(d))
+}
+
+func TestStaticMembers(t *testing.T) {
+	d := parseDecl(t, `interface Math {
+	static long compute(long x, optional long y);
+	static readonly attribute double PI;
+	static attribute long counter;
+};`)
+	iface := d.(*ast.Interface)
+	require.Empty(t, ast.AllErrors(iface))
+
+	op := iface.Member("compute")
+	require.NotNil(t, op)
+	require.True(t, op.Static)
+	require.False(t, op.Attribute)
+	require.Equal(t, "long", op.ReturnType().(*ast.TypeName).Name)
+	require.Len(t, op.Parameters, 2)
+	require.Equal(t, "x", op.Parameters[0].Name)
+	require.True(t, op.Parameters[1].Optional)
+
+	pi := iface.Member("PI")
+	require.True(t, pi.Static)
+	require.True(t, pi.Attribute)
+	require.True(t, pi.Readonly)
+	require.Nil(t, pi.Parameters)
+
+	counter := iface.Member("counter")
+	require.True(t, counter.Static)
+	require.True(t, counter.Attribute)
+	require.False(t, counter.Readonly)
+}
+
+func TestInheritAttribute(t *testing.T) {
+	f := Parse(`interface Foo : Bar {
+	inherit attribute long a;
+	[Clamp] inherit attribute long b;
+	attribute inherit c;
+	readonly inherit attribute long d;
+	inherit readonly attribute long e;
+};`)
+	iface := f.Declarations[0].(*ast.Interface)
+	errs := ast.AllErrors(iface)
+	require.Len(t, errs, 2)
+	require.Equal(t, "Inherit modifier must precede other modifiers", errs[0].Message)
+	require.Equal(t, 5, errs[0].Line)
+	require.Equal(t, "Inherited attribute cannot be readonly", errs[1].Message)
+	require.Equal(t, 6, errs[1].Line)
+
+	require.True(t, iface.Member("a").Inherit)
+	b := iface.Member("b")
+	require.True(t, b.Inherit)
+	require.Len(t, b.Annotations, 1)
+	// inherit is a type name if it doesn't start an attribute
+	c := iface.Member("c")
+	require.False(t, c.Inherit)
+	require.Equal(t, "inherit", c.Type.(*ast.TypeName).Name)
+}
+
+func TestNullableUnionTypedef(t *testing.T) {
+	d := parseDecl(t, `typedef (Foo or sequence<Bar>)? MaybeThing;`)
+	td := d.(*ast.Typedef)
+	require.Empty(t, ast.AllErrors(td))
+	require.Equal(t, "MaybeThing", td.Name)
+	nt, ok := td.Type.(*ast.NullableType)
+	require.True(t, ok)
+	u, ok := nt.Type.(*ast.UnionType)
+	require.True(t, ok)
+	require.Len(t, u.Types, 2)
+	require.Equal(t, "Foo", u.Types[0].(*ast.TypeName).Name)
+	require.IsType(t, &ast.SequenceType{}, u.Types[1])
+}
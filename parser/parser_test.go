@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dennwc/webidl/ast"
 	"github.com/stretchr/testify/require"
 )
 
@@ -51,3 +52,86 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseErr(t *testing.T) {
+	f, err := ParseErr(`interface Foo { attribute long x; };`)
+	require.NoError(t, err)
+	require.Len(t, f.Declarations, 1)
+
+	f, err = ParseErr(`interface Foo { attribute long x }; interface Bar {};`)
+	require.Error(t, err)
+	require.NotNil(t, f)
+	list, ok := err.(ErrorList)
+	require.True(t, ok)
+	require.NotEmpty(t, list)
+	require.Equal(t, 1, list[0].Line)
+}
+
+func TestMustParse(t *testing.T) {
+	require.NotPanics(t, func() {
+		f := MustParse(`interface Foo {};`)
+		require.Len(t, f.Declarations, 1)
+	})
+	require.Panics(t, func() {
+		MustParse(`interface Foo {`)
+	})
+}
+
+func TestParseBOM(t *testing.T) {
+	const src = "\uFEFF// comment\r\ninterface Foo {\r\n\tattribute long x;\r\n};\r\n"
+	f, err := ParseErr(src)
+	require.NoError(t, err)
+	iface := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, "Foo", iface.Name)
+	require.Equal(t, strings.Index(src, "interface"), iface.Start)
+	require.Equal(t, 2, iface.Line)
+	require.Equal(t, []string{"// comment"}, iface.Comments)
+	require.Equal(t, 3, iface.Member("x").Line)
+}
+
+func TestFileComments(t *testing.T) {
+	f, err := ParseErr(`// Copyright 2020 The Authors.
+// All rights reserved.
+
+// Foo is the main interface.
+interface Foo {};`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"// Copyright 2020 The Authors.", "// All rights reserved."}, f.Comments)
+	require.Equal(t, []string{"// Foo is the main interface."}, f.Declarations[0].NodeBase().Comments)
+
+	// without a blank line the comment belongs to the declaration
+	f, err = ParseErr("// Copyright 2020 The Authors.\r\ninterface Foo {};")
+	require.NoError(t, err)
+	require.Empty(t, f.Comments)
+	require.Len(t, f.Declarations[0].NodeBase().Comments, 1)
+
+	f, err = ParseErr("// Copyright 2020 The Authors.\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{"// Copyright 2020 The Authors."}, f.Comments)
+}
+
+func TestTrailingComments(t *testing.T) {
+	f, err := ParseErr(`interface Foo {
+	attribute long x; // x
+	// y
+	attribute long y;
+	// end of Foo
+}; // Foo
+enum E { "a", // a
+	"b" /* b */ };
+// end of file
+`)
+	require.NoError(t, err)
+	iface := f.Declarations[0].(*ast.Interface)
+	require.Equal(t, []string{"// Foo"}, iface.Trailing)
+	require.Equal(t, []string{"// end of Foo"}, iface.Dangling)
+	require.Equal(t, []string{"// x"}, iface.Member("x").Trailing)
+	require.Empty(t, iface.Member("x").Comments)
+	require.Equal(t, []string{"// y"}, iface.Member("y").Comments)
+
+	enum := f.Declarations[1].(*ast.Enum)
+	require.Equal(t, []string{"// a"}, enum.Values[0].Trailing)
+	require.Equal(t, []string{"/* b */"}, enum.Values[1].Trailing)
+	require.Empty(t, enum.Values[1].Comments)
+	require.Equal(t, []string{"// end of file"}, f.Dangling)
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"github.com/dennwc/webidl/ast"
+)
+
+// ParseType parses a standalone type expression, like "sequence<DOMString>?".
+// It returns an ErrorList if the type is invalid or if there are tokens after it.
+// The type is always returned, even if it's incomplete.
+func ParseType(input string) (ast.Type, error) {
+	n, err := parseSnippet(input, "type", func(p *sourceParser) ast.Node {
+		return p.consumeType()
+	})
+	return n.(ast.Type), err
+}
+
+// ParseMember parses a single interface member, like "readonly attribute long x".
+// The trailing semicolon is optional. Constructors are not accepted.
+// It returns an ErrorList if the member is invalid or if there are tokens after it.
+// The member is always returned, even if it's incomplete.
+func ParseMember(input string) (*ast.Member, error) {
+	n, err := parseSnippet(input, "member", func(p *sourceParser) ast.Node {
+		if p.isConstructor() {
+			p.emitError("Expected a member, got a constructor")
+			p.skipToMemberEnd()
+			p.tryConsume(tokenTypeSemicolon)
+			return &ast.Member{}
+		}
+		base := &ast.Base{}
+		finish := p.node(base)
+		ann := p.consumeNodeAnnotations(base)
+		m := p.consumeMember(false, ann, base, finish)
+		p.tryConsume(tokenTypeSemicolon)
+		return m
+	})
+	return n.(*ast.Member), err
+}
+
+// parseSnippet parses a part of a declaration with a given function.
+// It reports an error if the function doesn't consume the whole input.
+func parseSnippet(input string, what string, fn func(p *sourceParser) ast.Node) (ast.Node, error) {
+	l := lex(input)
+	defer l.stop()
+	p := buildParser(l, Options{}.config(), 0)
+	// collects errors that are not attached to the snippet itself
+	holder := &ast.Base{}
+	finish := p.node(holder)
+	p.consumeToken()
+	n := fn(p)
+	if !p.isToken(tokenTypeEOF) {
+		p.emitError("Unexpected token after the %s: %v", what, p.currentToken)
+	}
+	finish()
+	b := n.NodeBase()
+	b.Errors = append(b.Errors, holder.Errors...)
+	if errs := ast.AllErrors(n); len(errs) != 0 {
+		return n, ErrorList(errs)
+	}
+	return n, nil
+}
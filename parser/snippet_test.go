@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseType(t *testing.T) {
+	typ, err := ParseType(`(DOMString or sequence<long>)`)
+	require.NoError(t, err)
+	u, ok := typ.(*ast.UnionType)
+	require.True(t, ok)
+	require.Len(t, u.Types, 2)
+
+	typ, err = ParseType(`sequence<DOMString>?`)
+	require.NoError(t, err)
+	inner, nullable := ast.Unwrap(typ)
+	require.True(t, nullable)
+	require.IsType(t, &ast.SequenceType{}, inner)
+
+	typ, err = ParseType(`unsigned long x`)
+	require.Error(t, err)
+	require.Equal(t, "unsigned long", typ.(*ast.TypeName).Name)
+	errs := err.(ErrorList)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Message, "Unexpected token after the type")
+}
+
+func TestParseMember(t *testing.T) {
+	m, err := ParseMember(`[SameObject] readonly attribute long x`)
+	require.NoError(t, err)
+	require.Equal(t, "x", m.Name)
+	require.True(t, m.Attribute)
+	require.True(t, m.Readonly)
+	require.Equal(t, "SameObject", m.Annotations[0].Name)
+
+	m, err = ParseMember(`Promise<undefined> close(optional long code = 0);`)
+	require.NoError(t, err)
+	require.Equal(t, "close", m.Name)
+	require.False(t, m.Attribute)
+	require.Len(t, m.Parameters, 1)
+	require.Equal(t, "Promise", m.ReturnType().(*ast.ParametrizedType).Name)
+
+	_, err = ParseMember(`attribute long x; attribute long y;`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unexpected token after the member")
+
+	_, err = ParseMember(`constructor(long x);`)
+	require.Error(t, err)
+	require.Len(t, err.(ErrorList), 1)
+	require.Contains(t, err.Error(), "Expected a member, got a constructor")
+}
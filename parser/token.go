@@ -0,0 +1,109 @@
+package parser
+
+import "strconv"
+
+// TokenKind is a kind of a lexical token.
+type TokenKind int
+
+// Token kinds. The order matches the internal token types.
+const (
+	TokenError TokenKind = iota // lexing error; value is text of the error
+	TokenEOF
+	TokenWhitespace
+	TokenComment
+
+	TokenIdentifier // helloworld, interface
+	TokenString     // "hello"
+	TokenNumber     // 123
+
+	TokenLeftBrace    // {
+	TokenRightBrace   // }
+	TokenLeftParen    // (
+	TokenRightParen   // )
+	TokenLeftBracket  // [
+	TokenRightBracket // ]
+	TokenLeftTri      // <
+	TokenRightTri     // >
+
+	TokenEquals       // =
+	TokenSemicolon    // ;
+	TokenComma        // ,
+	TokenQuestionMark // ?
+	TokenColon        // :
+	TokenVariadic     // ...
+	TokenPipe         // |
+)
+
+// tokenKindNames are names of token kinds. They are listed explicitly, so they don't
+// depend on the names of internal token types.
+var tokenKindNames = [...]string{
+	TokenError:        "Error",
+	TokenEOF:          "EOF",
+	TokenWhitespace:   "Whitespace",
+	TokenComment:      "Comment",
+	TokenIdentifier:   "Identifier",
+	TokenString:       "String",
+	TokenNumber:       "Number",
+	TokenLeftBrace:    "LeftBrace",
+	TokenRightBrace:   "RightBrace",
+	TokenLeftParen:    "LeftParen",
+	TokenRightParen:   "RightParen",
+	TokenLeftBracket:  "LeftBracket",
+	TokenRightBracket: "RightBracket",
+	TokenLeftTri:      "LeftTri",
+	TokenRightTri:     "RightTri",
+	TokenEquals:       "Equals",
+	TokenSemicolon:    "Semicolon",
+	TokenComma:        "Comma",
+	TokenQuestionMark: "QuestionMark",
+	TokenColon:        "Colon",
+	TokenVariadic:     "Variadic",
+	TokenPipe:         "Pipe",
+}
+
+// String returns the name of the token kind, like "Identifier" or "LeftBrace".
+func (k TokenKind) String() string {
+	if k < 0 || int(k) >= len(tokenKindNames) {
+		return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return tokenKindNames[k]
+}
+
+// Token is a lexical token of the WebIDL source.
+type Token struct {
+	Kind  TokenKind
+	Start int // byte offset
+	Line  int // line number
+	Value string
+}
+
+// LexFunc splits the input into tokens and calls fn for each of them, including whitespace
+// and comments. It stops when fn returns false, or after the end of input or an error token.
+// The EOF token is not passed to fn.
+func LexFunc(input string, fn func(Token) bool) {
+	l := lex(input)
+	for {
+		t := l.nextToken()
+		if t.kind == tokenTypeEOF {
+			return
+		}
+		if !fn(Token{Kind: TokenKind(t.kind), Start: int(t.position), Line: int(t.line), Value: t.value}) {
+			l.stop()
+			return
+		}
+		if t.kind == tokenTypeError {
+			return
+		}
+	}
+}
+
+// Lex splits the input into tokens, including whitespace and comments.
+// The last token has the TokenError kind if the input cannot be tokenized.
+func Lex(input string) []Token {
+	var out []Token
+	LexFunc(input, func(t Token) bool {
+		out = append(out, t)
+		return true
+	})
+	return out
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexFunc(t *testing.T) {
+	const src = "interface Foo {\n  attribute long x; // c\n};"
+	var got []Token
+	LexFunc(src, func(tok Token) bool {
+		got = append(got, tok)
+		return true
+	})
+	// compare with the internal token stream
+	l := lex(src)
+	for i := 0; ; i++ {
+		exp := l.nextToken()
+		if exp.kind == tokenTypeEOF {
+			require.Len(t, got, i)
+			break
+		}
+		require.True(t, i < len(got))
+		require.Equal(t, Token{Kind: TokenKind(exp.kind), Start: int(exp.position), Line: int(exp.line), Value: exp.value}, got[i])
+	}
+	require.Equal(t, TokenComment, got[len(got)-4].Kind)
+	require.Equal(t, "// c", got[len(got)-4].Value)
+	require.Equal(t, Token{Kind: TokenIdentifier, Start: 0, Line: 1, Value: "interface"}, got[0])
+	require.Equal(t, Token{Kind: TokenLeftBrace, Start: 14, Line: 1, Value: "{"}, got[4])
+
+	var idents []string
+	LexFunc(src, func(tok Token) bool {
+		if tok.Kind == TokenIdentifier {
+			idents = append(idents, tok.Value)
+		}
+		return len(idents) < 2
+	})
+	require.Equal(t, []string{"interface", "Foo"}, idents)
+
+	toks := Lex("interface #")
+	require.Equal(t, TokenError, toks[len(toks)-1].Kind)
+
+	// public kinds must match internal token types
+	require.Equal(t, TokenPipe, TokenKind(tokenTypePipe))
+}
+
+func TestLexerStop(t *testing.T) {
+	// punctuation and whitespace are scanned in a single lexer state
+	l := lex("{" + strings.Repeat("[];\n", 100000))
+	require.Equal(t, "{", l.nextToken().value)
+	l.stop()
+	// the lexer exits without scanning the rest of the input
+	n := 0
+	for range l.tokens {
+		n++
+	}
+	require.True(t, n < 10)
+	// the channel is closed by the lexer goroutine after it stops, so it's safe to check
+	require.True(t, l.pos < 100)
+}
+
+func TestTokenKindString(t *testing.T) {
+	require.Equal(t, "Identifier", TokenIdentifier.String())
+	require.Equal(t, "LeftBrace", TokenLeftBrace.String())
+	require.Equal(t, "Variadic", TokenVariadic.String())
+	require.Equal(t, "Pipe", TokenPipe.String())
+	require.Equal(t, "TokenKind(100)", TokenKind(100).String())
+	// all kinds are named the same way as internal token types
+	for k := TokenError; k <= TokenPipe; k++ {
+		require.Equal(t, tokenType(k).String(), k.String())
+	}
+}
@@ -4,9 +4,9 @@ package parser
 
 import "strconv"
 
-const _tokenType_name = "ErrorEOFWhitespaceCommentKeywordIdentifierNumberLeftBraceRightBraceLeftParenRightParenLeftBracketRightBracketLeftTriRightTriEqualsSemicolonCommaQuestionMarkColonVariadic"
+const _tokenType_name = "ErrorEOFWhitespaceCommentIdentifierStringNumberLeftBraceRightBraceLeftParenRightParenLeftBracketRightBracketLeftTriRightTriEqualsSemicolonCommaQuestionMarkColonVariadicPipe"
 
-var _tokenType_index = [...]uint8{0, 5, 8, 18, 25, 32, 42, 48, 57, 67, 76, 86, 97, 109, 116, 124, 130, 139, 144, 156, 161, 169}
+var _tokenType_index = [...]uint8{0, 5, 8, 18, 25, 35, 41, 47, 56, 66, 75, 85, 96, 108, 115, 123, 129, 138, 143, 155, 160, 168, 172}
 
 func (i tokenType) String() string {
 	if i < 0 || i >= tokenType(len(_tokenType_index)-1) {
@@ -0,0 +1,497 @@
+// Package printer prints WebIDL parse trees in a canonical form.
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+)
+
+// indent is used for members of declarations.
+const indent = "  "
+
+// Format parses the WebIDL source and prints it in a canonical form.
+// It returns an error if the source cannot be parsed.
+//
+// Comments are kept if they precede declarations, members or enum values, follow them
+// on the same line, or are a part of the file header. Format returns an error instead
+// of dropping comments in other places, like inside parameter lists.
+//
+// Names spelled like keywords are escaped with an underscore, unless WebIDL allows
+// the keyword in that position, like argument names.
+func Format(src string) (string, error) {
+	f, err := parser.ParseErr(src)
+	if err != nil {
+		return "", err
+	}
+	ast.Canonicalize(f)
+	p := &printer{}
+	if err := p.print(f); err != nil {
+		return "", err
+	}
+	if err := checkComments(src, p.printed); err != nil {
+		return "", err
+	}
+	return p.buf.String(), nil
+}
+
+// checkComments returns an error if some comments of the source were not printed.
+func checkComments(src string, printed []string) error {
+	left := make(map[string]int, len(printed))
+	for _, c := range printed {
+		left[c]++
+	}
+	var err error
+	parser.LexFunc(src, func(t parser.Token) bool {
+		if t.Kind != parser.TokenComment {
+			return true
+		}
+		if left[t.Value] == 0 {
+			err = fmt.Errorf("%d: comment cannot be preserved: %s", t.Line, t.Value)
+			return false
+		}
+		left[t.Value]--
+		return true
+	})
+	return err
+}
+
+// Fprint prints the file or a single declaration to w.
+func Fprint(w io.Writer, n ast.Node) error {
+	p := &printer{}
+	if err := p.print(n); err != nil {
+		return err
+	}
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+type printer struct {
+	buf     bytes.Buffer
+	printed []string // comments printed so far
+}
+
+func (p *printer) print(n ast.Node) error {
+	switch n := n.(type) {
+	case *ast.File:
+		p.comments(n, "")
+		for i, d := range n.Declarations {
+			if i != 0 || len(n.Comments) != 0 {
+				p.printf("\n")
+			}
+			p.decl(d)
+		}
+		if len(n.Declarations) != 0 && len(n.Dangling) != 0 {
+			p.printf("\n")
+		}
+		p.danglingComments(n, "")
+	case ast.Decl:
+		p.decl(n)
+	default:
+		return fmt.Errorf("unsupported node type: %T", n)
+	}
+	return nil
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&p.buf, format, args...)
+}
+
+// comments prints comments attached to the node, each on a separate line.
+func (p *printer) comments(n ast.Node, prefix string) {
+	for _, c := range n.NodeBase().Comments {
+		p.printf("%s%s\n", prefix, c)
+		p.printed = append(p.printed, c)
+	}
+}
+
+// danglingComments prints comments at the end of the node body, each on a separate line.
+func (p *printer) danglingComments(n ast.Node, prefix string) {
+	for _, c := range n.NodeBase().Dangling {
+		p.printf("%s%s\n", prefix, c)
+		p.printed = append(p.printed, c)
+	}
+}
+
+// trailingComments prints comments that follow the node on the same line.
+func (p *printer) trailingComments(n ast.Node) {
+	for _, c := range n.NodeBase().Trailing {
+		p.printf(" %s", c)
+		p.printed = append(p.printed, c)
+	}
+}
+
+func (p *printer) decl(d ast.Decl) {
+	p.comments(d, "")
+	p.declBody(d)
+	if len(d.NodeBase().Trailing) != 0 {
+		// move the comments before the line break
+		p.buf.Truncate(p.buf.Len() - 1)
+		p.trailingComments(d)
+		p.printf("\n")
+	}
+}
+
+func (p *printer) declBody(d ast.Decl) {
+	switch d := d.(type) {
+	case *ast.Interface:
+		p.annotations(d.Annotations, "\n")
+		if d.Callback {
+			p.printf("callback ")
+		}
+		if d.Partial {
+			p.printf("partial ")
+		}
+		p.printf("interface %s", name(d.Name, nil))
+		if d.Inherits != "" {
+			p.printf(" : %s", name(d.Inherits, nil))
+		}
+		if d.Forward {
+			p.printf(";\n")
+			return
+		}
+		var list []ast.Node
+		for _, m := range d.Members {
+			list = append(list, m)
+		}
+		for _, op := range d.CustomOps {
+			list = append(list, op)
+		}
+		if d.Iterable != nil {
+			list = append(list, d.Iterable)
+		}
+		if d.Maplike != nil {
+			list = append(list, d.Maplike)
+		}
+		if d.Setlike != nil {
+			list = append(list, d.Setlike)
+		}
+		p.body(d, list, false)
+	case *ast.Mixin:
+		p.annotations(d.Annotations, "\n")
+		if d.Partial {
+			p.printf("partial ")
+		}
+		p.printf("interface mixin %s", name(d.Name, nil))
+		if d.Inherits != "" {
+			p.printf(" : %s", name(d.Inherits, nil))
+		}
+		var list []ast.Node
+		for _, m := range d.Members {
+			list = append(list, m)
+		}
+		for _, op := range d.CustomOps {
+			list = append(list, op)
+		}
+		if d.Iterable != nil {
+			list = append(list, d.Iterable)
+		}
+		p.body(d, list, false)
+	case *ast.Dictionary:
+		p.annotations(d.Annotations, "\n")
+		if d.Partial {
+			p.printf("partial ")
+		}
+		p.printf("dictionary %s", name(d.Name, nil))
+		if d.Inherits != "" {
+			p.printf(" : %s", name(d.Inherits, nil))
+		}
+		var list []ast.Node
+		for _, m := range d.Members {
+			list = append(list, m)
+		}
+		p.body(d, list, true)
+	case *ast.Namespace:
+		p.annotations(d.Annotations, "\n")
+		if d.Partial {
+			p.printf("partial ")
+		}
+		p.printf("namespace %s", name(d.Name, nil))
+		var list []ast.Node
+		for _, m := range d.Members {
+			list = append(list, m)
+		}
+		p.body(d, list, false)
+	case *ast.Enum:
+		p.annotations(d.Annotations, "\n")
+		p.printf("enum %s {\n", name(d.Name, nil))
+		for i, v := range d.Values {
+			p.comments(v, indent)
+			p.printf("%s", indent)
+			p.annotations(v.Annotations, " ")
+			p.printf("%s", literal(v.Value))
+			if i != len(d.Values)-1 {
+				p.printf(",")
+			}
+			p.trailingComments(v)
+			p.printf("\n")
+		}
+		p.danglingComments(d, indent)
+		p.printf("};\n")
+	case *ast.Typedef:
+		p.annotations(d.Annotations, "\n")
+		p.printf("typedef %s %s;\n", typeString(d.Type), name(d.Name, nil))
+	case *ast.Callback:
+		p.annotations(d.Annotations, "\n")
+		p.printf("callback %s = %s (%s);\n", name(d.Name, nil), typeString(d.Return), parameters(d.Parameters))
+	case *ast.Includes:
+		p.printf("%s includes %s;\n", name(d.Name, nil), name(d.Source, nil))
+	case *ast.Implementation:
+		p.printf("%s implements %s;\n", name(d.Name, nil), name(d.Source, nil))
+	case *ast.CustomDecl:
+		// data of the dialect is unknown, print only the header
+		p.annotations(d.Annotations, "\n")
+		p.printf("%s %s;\n", d.Keyword, d.Name)
+	}
+}
+
+// body prints members of a declaration in source order, including braces.
+func (p *printer) body(d ast.Decl, list []ast.Node, dict bool) {
+	if len(list) == 0 && len(d.NodeBase().Dangling) == 0 {
+		p.printf(" {};\n")
+		return
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].NodeBase().Start < list[j].NodeBase().Start
+	})
+	p.printf(" {\n")
+	for _, n := range list {
+		p.comments(n, indent)
+		p.printf("%s", indent)
+		switch n := n.(type) {
+		case *ast.Member:
+			p.member(n, dict)
+		case *ast.Constructor:
+			p.annotations(n.Annotations, " ")
+			p.printf("constructor(%s)", parameters(n.Parameters))
+		case *ast.CustomOp:
+			p.printf("%s", n.Name)
+		case *ast.Iterable:
+			if n.Async {
+				p.printf("async ")
+			}
+			if n.Key != nil {
+				p.printf("iterable<%s, %s>", typeString(n.Key), typeString(n.Elem))
+			} else {
+				p.printf("iterable<%s>", typeString(n.Elem))
+			}
+			if n.Parameters != nil {
+				p.printf("(%s)", parameters(n.Parameters))
+			}
+		case *ast.Maplike:
+			if n.Readonly {
+				p.printf("readonly ")
+			}
+			p.printf("maplike<%s, %s>", typeString(n.Key), typeString(n.Elem))
+		case *ast.Setlike:
+			if n.Readonly {
+				p.printf("readonly ")
+			}
+			p.printf("setlike<%s>", typeString(n.Elem))
+		}
+		p.printf(";")
+		p.trailingComments(n)
+		p.printf("\n")
+	}
+	p.danglingComments(d, indent)
+	p.printf("};\n")
+}
+
+func (p *printer) member(m *ast.Member, dict bool) {
+	p.annotations(m.Annotations, " ")
+	if m.Inherit {
+		p.printf("inherit ")
+	}
+	if m.Specialization != "" {
+		p.printf("%s ", m.Specialization)
+	}
+	if m.Const {
+		p.printf("const ")
+	}
+	if m.Static {
+		p.printf("static ")
+	}
+	if m.Readonly {
+		p.printf("readonly ")
+	}
+	if m.Required {
+		p.printf("required ")
+	}
+	if m.Attribute && !dict {
+		p.printf("attribute ")
+	}
+	if m.Type != nil {
+		// also separates the type from parameters of unnamed special operations
+		p.printf("%s ", typeString(m.Type))
+	}
+	switch {
+	case m.Attribute && !dict:
+		p.printf("%s", name(m.Name, attributeNameKeywords))
+	case !m.Attribute && !m.Const:
+		p.printf("%s", name(m.Name, operationNameKeywords))
+	default:
+		p.printf("%s", name(m.Name, nil))
+	}
+	if !m.Attribute && !m.Const {
+		p.printf("(%s)", parameters(m.Parameters))
+	}
+	if m.Init != nil {
+		p.printf(" = %s", literal(m.Init))
+	}
+}
+
+// annotations prints extended attributes in brackets, followed by a separator.
+func (p *printer) annotations(list []*ast.Annotation, sep string) {
+	if len(list) == 0 {
+		return
+	}
+	p.printf("%s%s", annotations(list), sep)
+}
+
+func annotations(list []*ast.Annotation) string {
+	out := make([]string, 0, len(list))
+	for _, a := range list {
+		s := a.Name
+		switch {
+		case len(a.Values) != 0:
+			s += "=(" + strings.Join(a.Values, ", ") + ")"
+		case a.Value != "":
+			s += "=" + a.Value
+			if a.Parameters != nil {
+				s += "(" + parameters(a.Parameters) + ")"
+			}
+		case a.Parameters != nil:
+			s += "(" + parameters(a.Parameters) + ")"
+		}
+		out = append(out, s)
+	}
+	return "[" + strings.Join(out, ", ") + "]"
+}
+
+func parameters(list []*ast.Parameter) string {
+	out := make([]string, 0, len(list))
+	for _, p := range list {
+		var s string
+		if len(p.Annotations) != 0 {
+			s += annotations(p.Annotations) + " "
+		}
+		if p.Optional {
+			s += "optional "
+		}
+		s += typeString(p.Type)
+		if p.Variadic {
+			s += "..."
+		}
+		s += " " + name(p.Name, argumentNameKeywords)
+		if p.Init != nil {
+			s += " = " + literal(p.Init)
+		}
+		out = append(out, s)
+	}
+	return strings.Join(out, ", ")
+}
+
+func typeString(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.TypeName:
+		if len(t.Annotations) != 0 {
+			return annotations(t.Annotations) + " " + typeName(t.Name)
+		}
+		return typeName(t.Name)
+	case *ast.AnyType:
+		return "any"
+	case *ast.SequenceType:
+		return "sequence<" + typeString(t.Elem) + ">"
+	case *ast.RecordType:
+		return "record<" + typeString(t.Key) + ", " + typeString(t.Elem) + ">"
+	case *ast.ParametrizedType:
+		elems := make([]string, 0, len(t.Elems))
+		for _, e := range t.Elems {
+			elems = append(elems, typeString(e))
+		}
+		return typeName(t.Name) + "<" + strings.Join(elems, ", ") + ">"
+	case *ast.UnionType:
+		types := make([]string, 0, len(t.Types))
+		for _, e := range t.Types {
+			types = append(types, typeString(e))
+		}
+		return "(" + strings.Join(types, " or ") + ")"
+	case *ast.NullableType:
+		return typeString(t.Type) + "?"
+	}
+	return ""
+}
+
+func literal(l ast.Literal) string {
+	switch l := l.(type) {
+	case *ast.BasicLiteral:
+		return l.Value
+	case *ast.BoolLiteral:
+		return l.String()
+	case *ast.NullLiteral:
+		return "null"
+	case *ast.DictionaryLiteral:
+		return "{}"
+	case *ast.SequenceLiteral:
+		elems := make([]string, 0, len(l.Elems))
+		for _, e := range l.Elems {
+			elems = append(elems, literal(e))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return ""
+}
+
+// keywords are identifiers with a special meaning in WebIDL. Names spelled like them are
+// escaped with an underscore, which is stripped by the parser.
+var keywords = map[string]struct{}{
+	"any": {}, "async": {}, "attribute": {}, "bigint": {}, "boolean": {}, "byte": {},
+	"ByteString": {}, "callback": {}, "const": {}, "constructor": {}, "deleter": {},
+	"dictionary": {}, "DOMString": {}, "double": {}, "enum": {}, "false": {}, "float": {},
+	"FrozenArray": {}, "getter": {}, "implements": {}, "includes": {}, "Infinity": {},
+	"inherit": {}, "interface": {}, "iterable": {}, "jsonifier": {}, "long": {},
+	"maplike": {}, "mixin": {}, "namespace": {}, "NaN": {}, "null": {}, "object": {},
+	"ObservableArray": {}, "octet": {}, "optional": {}, "or": {}, "partial": {},
+	"Promise": {}, "readonly": {}, "record": {}, "required": {}, "sequence": {},
+	"serializer": {}, "setlike": {}, "setter": {}, "short": {}, "static": {},
+	"stringifier": {}, "symbol": {}, "true": {}, "typedef": {}, "undefined": {},
+	"unrestricted": {}, "unsigned": {}, "USVString": {}, "void": {},
+}
+
+// Keywords that WebIDL allows as names of attributes, operations and arguments.
+var (
+	attributeNameKeywords = map[string]struct{}{"async": {}, "required": {}}
+	operationNameKeywords = map[string]struct{}{"includes": {}}
+	argumentNameKeywords  = map[string]struct{}{
+		"async": {}, "attribute": {}, "callback": {}, "const": {}, "constructor": {},
+		"deleter": {}, "dictionary": {}, "enum": {}, "getter": {}, "includes": {},
+		"inherit": {}, "interface": {}, "iterable": {}, "maplike": {}, "mixin": {},
+		"namespace": {}, "partial": {}, "readonly": {}, "required": {}, "setlike": {},
+		"setter": {}, "static": {}, "stringifier": {}, "typedef": {}, "unrestricted": {},
+	}
+)
+
+// name escapes the name if it's spelled like a keyword, unless the keyword is allowed.
+// Names starting with an underscore are escaped as well, since the parser strips one.
+func name(s string, allowed map[string]struct{}) string {
+	if _, ok := allowed[s]; ok {
+		return s
+	}
+	if _, ok := keywords[s]; ok || strings.HasPrefix(s, "_") {
+		return "_" + s
+	}
+	return s
+}
+
+// typeName escapes the name of a type if it's spelled like a keyword that isn't a type by itself.
+func typeName(s string) string {
+	if ast.IsBuiltin(s) && s != "any" && s != "sequence" && s != "record" {
+		return s
+	}
+	return name(s, nil)
+}
@@ -0,0 +1,183 @@
+package printer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dennwc/webidl/ast"
+	"github.com/dennwc/webidl/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	out, err := Format(`// The main interface.
+[Exposed=Window,   SecureContext]
+interface   Foo : Bar {
+	constructor ( optional long x = 0 );
+	readonly   attribute unsigned   long   size;
+	inherit attribute  DOMString name;
+	getter DOMString ( unsigned long index );
+	static Promise<sequence<(Node or DOMString)?>> find([Clamp] long n, any... rest);
+	iterable<DOMString>;
+};
+enum Mode { "a",  "b", };
+Foo includes Baz;`)
+	require.NoError(t, err)
+	require.Equal(t, `// The main interface.
+[Exposed=Window, SecureContext]
+interface Foo : Bar {
+  constructor(optional long x = 0);
+  readonly attribute unsigned long size;
+  inherit attribute DOMString name;
+  getter DOMString (unsigned long index);
+  static Promise<sequence<(Node or DOMString)?>> find([Clamp] long n, any... rest);
+  iterable<DOMString>;
+};
+
+enum Mode {
+  "a",
+  "b"
+};
+
+Foo includes Baz;
+`, out)
+
+	out, err = Format("// Copyright\n\n\ninterface Foo {};")
+	require.NoError(t, err)
+	require.Equal(t, "// Copyright\n\ninterface Foo {};\n", out)
+
+	out, err = Format(`interface Foo { async   iterable<long>( optional  Options o ); };`)
+	require.NoError(t, err)
+	require.Equal(t, `interface Foo {
+  async iterable<long>(optional Options o);
+};
+`, out)
+
+	_, err = Format(`interface Foo {`)
+	require.Error(t, err)
+}
+
+func TestFormatComments(t *testing.T) {
+	out, err := Format(`interface Foo {
+	attribute long x;   // x
+	// y
+	attribute long y;
+	// more to come
+};  // Foo
+enum E { "a",   "b" // c2
+};
+dictionary Empty {
+	// nothing yet
+};
+// the end`)
+	require.NoError(t, err)
+	require.Equal(t, `interface Foo {
+  attribute long x; // x
+  // y
+  attribute long y;
+  // more to come
+}; // Foo
+
+enum E {
+  "a",
+  "b" // c2
+};
+
+dictionary Empty {
+  // nothing yet
+};
+
+// the end
+`, out)
+
+	// comments inside of parameter lists cannot be printed
+	_, err = Format(`interface Foo {
+	void f(long a, // first
+		long b);
+};`)
+	require.EqualError(t, err, "2: comment cannot be preserved: // first")
+}
+
+// dumpTree dumps the tree without positions of nodes.
+func dumpTree(f *ast.File) string {
+	f.Source = ""
+	clear := func(b *ast.Base) {
+		b.Start, b.End, b.Line, b.EndLine = 0, 0, 0, 0
+	}
+	ast.Walk(f, func(n ast.Node) bool {
+		b := n.NodeBase()
+		clear(b)
+		for _, e := range b.Errors {
+			clear(&e.Base)
+		}
+		return true
+	})
+	return parser.DumpString(f)
+}
+
+func TestFormatEscapedNames(t *testing.T) {
+	const src = `typedef long _required;
+
+typedef long _getter;
+
+typedef long _static;
+
+dictionary D {
+  _required x;
+  long _long;
+};
+
+interface Foo {
+  _getter f();
+  _static g();
+  attribute (_or or long) required;
+  undefined includes(long callback, optional long __y);
+};
+
+interface _mixin : _object {};
+`
+	out, err := Format(src)
+	require.NoError(t, err)
+	require.Equal(t, src, out)
+
+	f, err := parser.ParseErr(out)
+	require.NoError(t, err)
+	d := f.Declarations[3].(*ast.Dictionary)
+	require.False(t, d.Members[0].Required)
+	require.Equal(t, "required", d.Members[0].Type.(*ast.TypeName).Name)
+	require.Equal(t, "long", d.Members[1].Name)
+	foo := f.Declarations[4].(*ast.Interface)
+	require.Equal(t, "", foo.Member("f").Specialization)
+	require.False(t, foo.Member("g").Static)
+	require.Equal(t, "_y", foo.Member("includes").Parameters[1].Name)
+	mixin := f.Declarations[5].(*ast.Interface)
+	require.Equal(t, "mixin", mixin.Name)
+	require.Equal(t, "object", mixin.Inherits)
+}
+
+func TestFormatIdempotent(t *testing.T) {
+	files, err := filepath.Glob("../parser/tests/*.webidl")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, path := range files {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			f, err := parser.ParseErr(string(data))
+			if err != nil {
+				t.Skip("fixture has errors")
+			}
+			out, err := Format(string(data))
+			require.NoError(t, err)
+			f2, err := parser.ParseErr(out)
+			require.NoError(t, err)
+			ast.Canonicalize(f)
+			require.Equal(t, dumpTree(f), dumpTree(f2))
+			out2, err := Format(out)
+			require.NoError(t, err)
+			require.Equal(t, out, out2)
+		})
+	}
+}